@@ -12,13 +12,58 @@ func (f ReceiverFunc) Receive(ctx *Context) {
 	f(ctx)
 }
 
+// Producer creates a new Receiver instance.
+type Producer func() Receiver
+
 type Middleware func(ReceiverFunc) ReceiverFunc
 
 type poisonPill struct {
-	wg *sync.WaitGroup
+	wg   *sync.WaitGroup
+	done chan struct{}
+}
+
+// stop is processed ahead of anything else in the inbox, see Engine.Stop.
+type stop struct {
+	done chan struct{}
+}
+
+// release anything waiting on a control message, once the actor it was sent to has stopped.
+func release(msg any) {
+	switch msg := msg.(type) {
+	case poisonPill:
+		if msg.wg != nil {
+			msg.wg.Done()
+		}
+		if msg.done != nil {
+			close(msg.done)
+		}
+
+	case stop:
+		close(msg.done)
+	}
 }
 
 type initialize struct{}
 type Initialized struct{}
 type Started struct{}
 type Stopped struct{}
+
+// Restarting is sent to an actor that panicked, before it is restarted.
+// It is followed by Stopped, so anything done when an actor stops is also done before it restarts.
+type Restarting struct {
+	// Reason is the value the actor panicked with.
+	Reason any
+	// Message is the message that was being processed when it panicked.
+	Message any
+}
+
+// Restarted is sent to an actor after Started once it has been restarted.
+type Restarted struct {
+	// Count is how many times the actor has been restarted.
+	Count int
+}
+
+// Terminated is sent to watchers of an actor once it has stopped.
+type Terminated struct {
+	PID PID
+}
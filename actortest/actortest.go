@@ -0,0 +1,49 @@
+package actortest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/renevo/actor"
+)
+
+// Spawn an actor under test, it will be stopped when the test finishes.
+func Spawn(t testing.TB, engine *actor.Engine, receiver actor.Receiver, name string, opts ...actor.Option) actor.PID {
+	pid := engine.Spawn(receiver, name, opts...)
+
+	t.Cleanup(func() {
+		stop(t, engine, pid)
+	})
+
+	return pid
+}
+
+// stop poisons the actor and waits for it to stop, deterministic engines are run until it has.
+func stop(t testing.TB, engine *actor.Engine, pid actor.PID) {
+	done := engine.Poison(pid, nil)
+	d, deterministic := deterministicEngines.Load(engine)
+	timeout := time.After(DefaultTimeout)
+
+	for {
+		if deterministic {
+			d.(*Deterministic).RunUntilIdle()
+		}
+
+		select {
+		case <-done:
+			return
+
+		case <-timeout:
+			t.Errorf("actortest: timed out after %s waiting for %s to stop", DefaultTimeout, pid)
+			return
+
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+// Inject a message into an actor with the given sender.
+func Inject(engine *actor.Engine, to actor.PID, msg any, from actor.PID) {
+	engine.SendWithSender(context.Background(), to, msg, from)
+}
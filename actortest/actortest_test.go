@@ -0,0 +1,73 @@
+package actortest_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/renevo/actor"
+	"github.com/renevo/actor/actortest"
+)
+
+type ping struct {
+	n int
+}
+
+type pong struct {
+	n int
+}
+
+func TestProbeExpectations(t *testing.T) {
+	is := is.New(t)
+
+	engine := actor.NewEngine()
+	probe := actortest.NewProbe(t, engine)
+
+	echo := actortest.Spawn(t, engine, actor.ReceiverFunc(func(ctx *actor.Context) {
+		if msg, ok := ctx.Message().(ping); ok {
+			ctx.Respond(pong{n: msg.n})
+		}
+	}), "TestProbeExpectations")
+
+	probe.Send(echo, ping{n: 1})
+	probe.ExpectMsg(pong{n: 1})
+	is.True(probe.LastSender().Equals(echo))
+
+	probe.Send(echo, ping{n: 2})
+	msg := actortest.ExpectMsgType[pong](probe)
+	is.Equal(2, msg.n)
+
+	probe.ExpectNoMsg(10 * time.Millisecond)
+}
+
+func TestProbeExpectTerminated(t *testing.T) {
+	engine := actor.NewEngine()
+	probe := actortest.NewProbe(t, engine)
+
+	pid := engine.SpawnFunc(func(ctx *actor.Context) {}, "TestProbeExpectTerminated")
+
+	wg := &sync.WaitGroup{}
+	engine.Poison(pid, wg)
+	probe.ExpectTerminated(pid)
+
+	// watching an actor that no longer exists terminates right away
+	probe.ExpectTerminated(pid)
+}
+
+func TestInject(t *testing.T) {
+	engine := actor.NewEngine()
+	probe := actortest.NewProbe(t, engine)
+
+	forwarder := actortest.Spawn(t, engine, actor.ReceiverFunc(func(ctx *actor.Context) {
+		if _, ok := ctx.Message().(ping); ok {
+			ctx.Respond(ctx.Sender())
+		}
+	}), "TestInject")
+
+	// the sender is the probe, even though the engine is sending
+	actortest.Inject(engine, forwarder, ping{}, probe.PID())
+	probe.ExpectMsg(probe.PID())
+	probe.Reply(ping{})
+	probe.ExpectMsg(probe.PID())
+}
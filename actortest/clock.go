@@ -0,0 +1,118 @@
+package actortest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/renevo/actor"
+)
+
+// VirtualClock is an actor.Clock that only moves forward when it is advanced by the test.
+// Timer functions are run on the goroutine calling Advance.
+type VirtualClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	seq    uint64
+	timers []*virtualTimer
+}
+
+type virtualTimer struct {
+	clock   *VirtualClock
+	at      time.Time
+	seq     uint64
+	fn      func()
+	stopped bool
+}
+
+// NewVirtualClock creates a VirtualClock starting at the given time.
+func NewVirtualClock(start time.Time) *VirtualClock {
+	return &VirtualClock{now: start}
+}
+
+func (c *VirtualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *VirtualClock) AfterFunc(d time.Duration, f func()) actor.Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.seq++
+	t := &virtualTimer{clock: c, at: c.now.Add(d), seq: c.seq, fn: f}
+	c.timers = append(c.timers, t)
+
+	return t
+}
+
+// Advance moves the clock forward, running all timers that are due in the order they are due.
+func (c *VirtualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	target := c.now.Add(d)
+	c.mu.Unlock()
+
+	for {
+		t := c.nextDue(target)
+		if t == nil {
+			break
+		}
+		t.fn()
+	}
+
+	c.mu.Lock()
+	if target.After(c.now) {
+		c.now = target
+	}
+	c.mu.Unlock()
+}
+
+// Pending returns the number of timers that have not fired or been stopped.
+func (c *VirtualClock) Pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+func (c *VirtualClock) nextDue(target time.Time) *virtualTimer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.timers) == 0 {
+		return nil
+	}
+
+	sort.Slice(c.timers, func(i, j int) bool {
+		if c.timers[i].at.Equal(c.timers[j].at) {
+			return c.timers[i].seq < c.timers[j].seq
+		}
+		return c.timers[i].at.Before(c.timers[j].at)
+	})
+
+	t := c.timers[0]
+	if t.at.After(target) {
+		return nil
+	}
+
+	c.timers = c.timers[1:]
+	if t.at.After(c.now) {
+		c.now = t.at
+	}
+
+	return t
+}
+
+func (t *virtualTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	for i, pending := range t.clock.timers {
+		if pending == t {
+			t.clock.timers = append(t.clock.timers[:i], t.clock.timers[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
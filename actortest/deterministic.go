@@ -0,0 +1,89 @@
+package actortest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/renevo/actor"
+)
+
+// deterministicEngines lets cleanup run the engine an actor is on when it is deterministic.
+var deterministicEngines sync.Map
+
+// Deterministic is an engine where all actors run on the test goroutine, and time only moves when advanced.
+// Nothing happens until RunUntilIdle or Advance is called, making actor tests fast and repeatable.
+//
+// Anything that blocks waiting on actors, such as Engine.Request or Engine.ShutdownAndWait, will block forever when called from the test goroutine;
+// use Shutdown instead, or call them from another goroutine while the test keeps running the engine.
+type Deterministic struct {
+	Engine     *actor.Engine
+	Dispatcher *ManualDispatcher
+	Clock      *VirtualClock
+}
+
+// NewDeterministic creates a new Deterministic engine, the given options are used as the engine defaults.
+func NewDeterministic(opts ...actor.Option) *Deterministic {
+	d := &Deterministic{
+		Dispatcher: NewManualDispatcher(),
+		Clock:      NewVirtualClock(time.Unix(0, 0)),
+	}
+
+	d.Engine = actor.NewEngine(append(opts, actor.WithDispatcher(d.Dispatcher), actor.WithClock(d.Clock))...)
+	deterministicEngines.Store(d.Engine, d)
+	d.RunUntilIdle()
+
+	return d
+}
+
+// NewEngine creates another Deterministic engine that shares the dispatcher and clock, so engines that talk to each other (such as a cluster) run together.
+func (d *Deterministic) NewEngine(opts ...actor.Option) *Deterministic {
+	other := &Deterministic{
+		Dispatcher: d.Dispatcher,
+		Clock:      d.Clock,
+	}
+
+	other.Engine = actor.NewEngine(append(opts, actor.WithDispatcher(d.Dispatcher), actor.WithClock(d.Clock))...)
+	deterministicEngines.Store(other.Engine, other)
+	other.RunUntilIdle()
+
+	return other
+}
+
+// RunUntilIdle processes messages until all inboxes are empty, timers that are already due will also run.
+func (d *Deterministic) RunUntilIdle() {
+	for {
+		d.Dispatcher.RunUntilIdle()
+		d.Clock.Advance(0)
+
+		if d.Dispatcher.Pending() == 0 {
+			return
+		}
+	}
+}
+
+// Advance the clock, and process all messages that were sent as a result.
+func (d *Deterministic) Advance(dur time.Duration) {
+	d.RunUntilIdle()
+	d.Clock.Advance(dur)
+	d.RunUntilIdle()
+}
+
+// Shutdown the engine, running it until all actors have stopped.
+func (d *Deterministic) Shutdown() {
+	done := make(chan struct{})
+	go func() {
+		d.Engine.ShutdownAndWait()
+		close(done)
+	}()
+
+	for {
+		d.RunUntilIdle()
+
+		select {
+		case <-done:
+			deterministicEngines.Delete(d.Engine)
+			return
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package actortest_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/renevo/actor"
+	"github.com/renevo/actor/actortest"
+)
+
+type tick struct{}
+
+func TestDeterministicRestartDelay(t *testing.T) {
+	d := actortest.NewDeterministic()
+	probe := actortest.NewProbe(t, d.Engine)
+
+	var panicked bool
+	pid := d.Engine.SpawnFunc(func(ctx *actor.Context) {
+		if msg, ok := ctx.Message().(ping); ok {
+			if !panicked {
+				panicked = true
+				panic("boom")
+			}
+			ctx.Send(ctx.Context(), probe.PID(), pong{n: msg.n})
+		}
+	}, "TestDeterministicRestartDelay", actor.WithRestartDelay(time.Second))
+
+	d.Engine.Send(context.Background(), pid, ping{n: 1})
+	d.Engine.Send(context.Background(), pid, ping{n: 2})
+	d.RunUntilIdle()
+	probe.ExpectNoMsg(0)
+
+	// still waiting on the restart delay
+	d.Advance(999 * time.Millisecond)
+	probe.ExpectNoMsg(0)
+
+	d.Advance(time.Millisecond)
+	probe.ExpectMsg(pong{n: 2})
+
+	d.Shutdown()
+}
+
+func TestDeterministicSendRepeat(t *testing.T) {
+	is := is.New(t)
+
+	d := actortest.NewDeterministic()
+	ticks := 0
+	pid := d.Engine.SpawnFunc(func(ctx *actor.Context) {
+		if _, ok := ctx.Message().(tick); ok {
+			ticks++
+		}
+	}, "TestDeterministicSendRepeat")
+
+	repeater := d.Engine.SendRepeat(pid, tick{}, 10*time.Millisecond)
+	d.Advance(35 * time.Millisecond)
+	is.Equal(3, ticks)
+
+	repeater.Stop()
+	d.Advance(time.Second)
+	is.Equal(3, ticks)
+
+	d.Shutdown()
+}
+
+func TestDeterministicRequestTimeout(t *testing.T) {
+	is := is.New(t)
+
+	d := actortest.NewDeterministic()
+	pending := d.Clock.Pending()
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := d.Engine.Request(actor.NewPID(actor.LocalAddress, "missing"), ping{}, time.Minute)
+		errCh <- err
+	}()
+
+	// wait for the request to start its timer
+	for d.Clock.Pending() == pending {
+		runtime.Gosched()
+	}
+
+	d.Advance(time.Minute)
+	is.True(errors.Is(<-errCh, context.DeadlineExceeded))
+
+	d.Shutdown()
+}
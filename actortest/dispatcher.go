@@ -0,0 +1,53 @@
+package actortest
+
+import "sync"
+
+// ManualDispatcher is an actor.Dispatcher that queues scheduled inboxes until they are run by the test, all actors run on the calling goroutine.
+type ManualDispatcher struct {
+	mu    sync.Mutex
+	queue []func()
+}
+
+// NewManualDispatcher creates an empty ManualDispatcher.
+func NewManualDispatcher() *ManualDispatcher {
+	return &ManualDispatcher{}
+}
+
+func (d *ManualDispatcher) Schedule(fn func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.queue = append(d.queue, fn)
+}
+
+// Step runs the next scheduled inbox, false is returned if nothing was scheduled.
+func (d *ManualDispatcher) Step() bool {
+	d.mu.Lock()
+	if len(d.queue) == 0 {
+		d.mu.Unlock()
+		return false
+	}
+
+	fn := d.queue[0]
+	d.queue[0] = nil
+	d.queue = d.queue[1:]
+	d.mu.Unlock()
+
+	fn()
+	return true
+}
+
+// RunUntilIdle runs scheduled inboxes until nothing is left, returning the number of steps that were run.
+func (d *ManualDispatcher) RunUntilIdle() int {
+	steps := 0
+	for d.Step() {
+		steps++
+	}
+	return steps
+}
+
+// Pending returns the number of scheduled inboxes.
+func (d *ManualDispatcher) Pending() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.queue)
+}
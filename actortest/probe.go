@@ -0,0 +1,173 @@
+// Package actortest provides utilities for testing actors without relying on sleeps and wait groups.
+package actortest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/renevo/actor"
+)
+
+// DefaultTimeout is the time a Probe will wait for an expected message.
+var DefaultTimeout = 3 * time.Second
+
+var probeID atomic.Int64
+
+// Received is a message received by a Probe.
+type Received struct {
+	Message any
+	Sender  actor.PID
+	Context context.Context
+}
+
+// Probe is an actor that records every message it receives, so tests can make expectations about them.
+type Probe struct {
+	t        testing.TB
+	engine   *actor.Engine
+	pid      actor.PID
+	messages chan Received
+	last     Received
+	timeout  time.Duration
+}
+
+// NewProbe spawns a new Probe on the given engine, the probe will be stopped when the test finishes.
+func NewProbe(t testing.TB, engine *actor.Engine) *Probe {
+	p := &Probe{
+		t:        t,
+		engine:   engine,
+		messages: make(chan Received, 1024),
+		timeout:  DefaultTimeout,
+	}
+
+	p.pid = engine.SpawnFunc(func(ctx *actor.Context) {
+		switch ctx.Message().(type) {
+		case actor.Initialized, actor.Started, actor.Stopped, actor.Restarting, actor.Restarted:
+			return
+		}
+
+		p.messages <- Received{Message: ctx.Message(), Sender: ctx.Sender(), Context: ctx.Context()}
+	}, "probe", actor.WithTags(strconv.FormatInt(probeID.Add(1), 10)))
+
+	t.Cleanup(func() {
+		stop(t, engine, p.pid)
+	})
+
+	return p
+}
+
+// PID of the probe actor.
+func (p *Probe) PID() actor.PID {
+	return p.pid
+}
+
+// WithTimeout sets the time the probe will wait for expected messages.
+func (p *Probe) WithTimeout(d time.Duration) *Probe {
+	p.timeout = d
+	return p
+}
+
+// Send a message to another actor with the probe as the sender.
+func (p *Probe) Send(to actor.PID, msg any) {
+	p.engine.SendWithSender(context.Background(), to, msg, p.pid)
+}
+
+// Reply to the sender of the last received message.
+func (p *Probe) Reply(msg any) {
+	p.t.Helper()
+
+	if p.last.Sender.IsZero() {
+		p.t.Fatalf("actortest: probe %s has no message to reply to", p.pid)
+	}
+
+	p.Send(p.last.Sender, msg)
+}
+
+// LastSender returns the sender of the last received message.
+func (p *Probe) LastSender() actor.PID {
+	return p.last.Sender
+}
+
+// Receive waits for the next message, failing the test if none arrives within the timeout.
+func (p *Probe) Receive() Received {
+	p.t.Helper()
+
+	select {
+	case r := <-p.messages:
+		p.last = r
+		return r
+
+	case <-time.After(p.timeout):
+		p.t.Fatalf("actortest: probe %s timed out after %s waiting for a message", p.pid, p.timeout)
+		return Received{}
+	}
+}
+
+// ExpectMsg waits for the next message and fails the test if it isn't equal to expected.
+func (p *Probe) ExpectMsg(expected any) any {
+	p.t.Helper()
+
+	r := p.Receive()
+	if !reflect.DeepEqual(expected, r.Message) {
+		p.t.Fatalf("actortest: probe %s expected message %s but received %s", p.pid, describe(expected), describe(r.Message))
+	}
+
+	return r.Message
+}
+
+// ExpectNoMsg fails the test if a message is received within the given duration.
+func (p *Probe) ExpectNoMsg(d time.Duration) {
+	p.t.Helper()
+
+	// check for anything already received first, so a zero duration never misses a message
+	select {
+	case r := <-p.messages:
+		p.last = r
+		p.t.Fatalf("actortest: probe %s expected no message but received %s", p.pid, describe(r.Message))
+
+	default:
+	}
+
+	select {
+	case r := <-p.messages:
+		p.last = r
+		p.t.Fatalf("actortest: probe %s expected no message but received %s", p.pid, describe(r.Message))
+
+	case <-time.After(d):
+	}
+}
+
+// ExpectTerminated watches the given actor and waits for it to stop, failing the test if it doesn't stop within the timeout or another message is received first.
+func (p *Probe) ExpectTerminated(pid actor.PID) {
+	p.t.Helper()
+
+	p.engine.Watch(pid, p.pid)
+	r := p.Receive()
+
+	terminated, ok := r.Message.(actor.Terminated)
+	if !ok || !terminated.PID.Equals(pid) {
+		p.t.Fatalf("actortest: probe %s expected %s to terminate but received %s", p.pid, pid, describe(r.Message))
+	}
+}
+
+// ExpectMsgType waits for the next message and fails the test if it isn't of type T.
+func ExpectMsgType[T any](p *Probe) T {
+	p.t.Helper()
+
+	r := p.Receive()
+	msg, ok := r.Message.(T)
+	if !ok {
+		var expected T
+		p.t.Fatalf("actortest: probe %s expected message of type %T but received %s", p.pid, expected, describe(r.Message))
+	}
+
+	return msg
+}
+
+func describe(v any) string {
+	return fmt.Sprintf("%T(%+v)", v, v)
+}
@@ -0,0 +1,186 @@
+// Package cluster forms a cluster out of engines connected with remote.
+//
+// Engines join through seed engines, and spread membership by gossiping with random members.
+// Each engine runs a phi accrual failure detector over the heartbeats in the gossip, and publishes MemberUp and MemberDown events on its own engine.
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/renevo/actor"
+	"github.com/renevo/actor/remote"
+)
+
+const (
+	clusterName = "cluster"
+
+	defaultGossipInterval = time.Second
+	defaultGossipFanout   = 3
+	defaultPhiThreshold   = 8.0
+
+	// minGossipInterval is the shortest gossip interval, heartbeats any closer together can't be told apart from scheduling noise.
+	minGossipInterval = time.Millisecond
+)
+
+var (
+	ErrNoRemote      = errors.New("cluster requires an engine with a remote")
+	ErrInvalidConfig = errors.New("invalid cluster config")
+)
+
+func init() {
+	remote.Register(join{})
+	remote.Register(gossip{})
+	remote.Register(leave{})
+}
+
+type Config struct {
+	// Seeds are the addresses of engines used to join the cluster, an engine can list itself.
+	Seeds []string
+	// GossipInterval is how often membership is gossiped, and heartbeats are sent.
+	GossipInterval time.Duration
+	// GossipFanout is the number of members gossiped to each interval.
+	GossipFanout int
+	// PhiThreshold is the suspicion level at which a member is considered down.
+	PhiThreshold float64
+	// MinStdDeviation of heartbeat intervals, which keeps very regular heartbeats from being too sensitive, defaults to half the GossipInterval.
+	MinStdDeviation time.Duration
+	// AcceptablePause is the amount of missing heartbeats that are tolerated before suspicion grows, defaults to twice the GossipInterval.
+	AcceptablePause time.Duration
+}
+
+// validate the config, zero values are replaced with defaults.
+func (c Config) validate() error {
+	switch {
+	case c.GossipInterval < 0 || (c.GossipInterval > 0 && c.GossipInterval < minGossipInterval):
+		return fmt.Errorf("%w: GossipInterval must be at least %s", ErrInvalidConfig, minGossipInterval)
+	case c.GossipFanout < 0:
+		return fmt.Errorf("%w: GossipFanout can't be negative", ErrInvalidConfig)
+	case c.PhiThreshold < 0 || math.IsNaN(c.PhiThreshold):
+		return fmt.Errorf("%w: PhiThreshold must be a positive number", ErrInvalidConfig)
+	case c.MinStdDeviation < 0:
+		return fmt.Errorf("%w: MinStdDeviation can't be negative", ErrInvalidConfig)
+	case c.AcceptablePause < 0:
+		return fmt.Errorf("%w: AcceptablePause can't be negative", ErrInvalidConfig)
+	}
+
+	return nil
+}
+
+type Cluster struct {
+	engine *actor.Engine
+	config Config
+	pid    actor.PID
+
+	mu      sync.RWMutex
+	self    Member
+	members map[string]Member
+	ring    *actor.HashRing
+	// joined is set once another member has been seen, or right away for the first seed which starts the cluster
+	joined bool
+}
+
+// New joins the engine to the cluster, the engine must have been created with a remote.
+func New(engine *actor.Engine, config Config) (*Cluster, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	if engine.Address() == actor.LocalAddress {
+		return nil, ErrNoRemote
+	}
+
+	if config.GossipInterval <= 0 {
+		config.GossipInterval = defaultGossipInterval
+	}
+	if config.GossipFanout <= 0 {
+		config.GossipFanout = defaultGossipFanout
+	}
+	if config.PhiThreshold <= 0 {
+		config.PhiThreshold = defaultPhiThreshold
+	}
+	if config.MinStdDeviation <= 0 {
+		config.MinStdDeviation = config.GossipInterval / 2
+	}
+	if config.AcceptablePause <= 0 {
+		config.AcceptablePause = config.GossipInterval * 2
+	}
+
+	c := &Cluster{
+		engine: engine,
+		config: config,
+		self: Member{
+			Address:   engine.Address(),
+			Status:    MemberStatusUp,
+			Heartbeat: 1,
+			Joined:    engine.Clock().Now().UnixNano(),
+		},
+		members: make(map[string]Member),
+		ring:    actor.NewHashRing(0),
+	}
+	c.members[c.self.Address] = c.self
+	c.ring.Add(c.self.Address)
+	c.joined = len(config.Seeds) > 0 && config.Seeds[0] == c.self.Address
+
+	c.pid = engine.Spawn(newMembership(c), clusterName)
+
+	return c, nil
+}
+
+// Engine the cluster is running on.
+func (c *Cluster) Engine() *actor.Engine {
+	return c.engine
+}
+
+// Self returns the member for this engine.
+func (c *Cluster) Self() Member {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.self
+}
+
+// Members returns all members that are up, including this engine, sorted by address.
+func (c *Cluster) Members() []Member {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	members := make([]Member, 0, len(c.members))
+	for _, m := range c.members {
+		if m.Status == MemberStatusUp {
+			members = append(members, m)
+		}
+	}
+	sortMembers(members)
+
+	return members
+}
+
+// Leave the cluster, other members are told this engine is leaving and membership stops.
+func (c *Cluster) Leave() {
+	wg := &sync.WaitGroup{}
+	c.engine.Send(context.Background(), c.pid, leaveRequest{})
+	c.engine.Poison(c.pid, wg)
+	wg.Wait()
+}
+
+func (c *Cluster) update(self Member, members map[string]*memberState) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.self = self
+	c.members = make(map[string]Member, len(members)+1)
+	for address, m := range members {
+		c.members[address] = m.Member
+	}
+	c.members[self.Address] = self
+	c.joined = c.joined || len(members) > 0
+	c.syncRing()
+}
+
+func clusterPID(address string) actor.PID {
+	return actor.NewPID(address, clusterName)
+}
@@ -0,0 +1,249 @@
+package cluster_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/renevo/actor"
+	"github.com/renevo/actor/actortest"
+	"github.com/renevo/actor/cluster"
+	"github.com/renevo/actor/remote"
+)
+
+const gossipInterval = time.Second
+
+// network connects engines in memory, so a cluster can run on deterministic engines.
+type network struct {
+	mu           sync.Mutex
+	engines      map[string]*actor.Engine
+	disconnected map[string]bool
+	// route, when set, can send a message somewhere else than it was addressed to
+	route func(to actor.PID) actor.PID
+}
+
+func newNetwork() *network {
+	return &network{engines: make(map[string]*actor.Engine), disconnected: make(map[string]bool)}
+}
+
+func (n *network) remote(address string) actor.Remote {
+	return &memoryRemote{network: n, address: address}
+}
+
+func (n *network) disconnect(address string, disconnected bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.disconnected[address] = disconnected
+}
+
+type memoryRemote struct {
+	network *network
+	address string
+}
+
+func (r *memoryRemote) Address() string { return r.address }
+
+func (r *memoryRemote) Start(e *actor.Engine) {
+	r.network.mu.Lock()
+	defer r.network.mu.Unlock()
+	r.network.engines[r.address] = e
+}
+
+func (r *memoryRemote) Send(ctx context.Context, to actor.PID, msg any, from actor.PID) {
+	r.network.mu.Lock()
+	if r.network.route != nil {
+		to = r.network.route(to)
+	}
+	target, ok := r.network.engines[to.Address]
+	cut := r.network.disconnected[r.address] || r.network.disconnected[to.Address]
+	r.network.mu.Unlock()
+
+	if ok && !cut {
+		target.SendWithSender(ctx, to, msg, from)
+	}
+}
+
+func (r *memoryRemote) Stop() {
+	r.network.mu.Lock()
+	defer r.network.mu.Unlock()
+	delete(r.network.engines, r.address)
+}
+
+func addresses(members []cluster.Member) []string {
+	var result []string
+	for _, m := range members {
+		result = append(result, m.Address)
+	}
+	return result
+}
+
+func TestMembership(t *testing.T) {
+	is := is.New(t)
+
+	net := newNetwork()
+	config := func(seeds ...string) cluster.Config {
+		return cluster.Config{Seeds: seeds, GossipInterval: gossipInterval}
+	}
+
+	d := actortest.NewDeterministic(actor.WithRemote(net.remote("a")))
+	t.Cleanup(d.Shutdown)
+	seed, err := cluster.New(d.Engine, config("a"))
+	is.NoErr(err)
+
+	probe := actortest.NewProbe(t, d.Engine)
+	d.Engine.Subscribe("cluster.member.*", probe.PID())
+
+	nodes := []*cluster.Cluster{seed}
+	for _, address := range []string{"b", "c"} {
+		other := d.NewEngine(actor.WithRemote(net.remote(address)))
+		t.Cleanup(other.Shutdown)
+
+		c, err := cluster.New(other.Engine, config("a"))
+		is.NoErr(err)
+		nodes = append(nodes, c)
+	}
+
+	// c only knows about the seed, so it learns about b through gossip
+	for i := 0; i < 3; i++ {
+		d.Advance(gossipInterval)
+	}
+	for _, c := range nodes {
+		is.Equal([]string{"a", "b", "c"}, addresses(c.Members()))
+	}
+
+	up := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		up[actortest.ExpectMsgType[cluster.MemberUp](probe).Member.Address] = true
+	}
+	is.Equal(map[string]bool{"a": true, "b": true, "c": true}, up)
+
+	// heartbeats stop arriving from c, so it is detected as failed
+	net.disconnect("c", true)
+	for i := 0; i < 10; i++ {
+		d.Advance(gossipInterval)
+	}
+	is.Equal("c", actortest.ExpectMsgType[cluster.MemberDown](probe).Member.Address)
+	is.Equal([]string{"a", "b"}, addresses(seed.Members()))
+	is.Equal([]string{"a", "b"}, addresses(nodes[1].Members()))
+
+	// and comes back once it can be reached again
+	net.disconnect("c", false)
+	for i := 0; i < 3; i++ {
+		d.Advance(gossipInterval)
+	}
+	is.Equal("c", actortest.ExpectMsgType[cluster.MemberUp](probe).Member.Address)
+	is.Equal([]string{"a", "b", "c"}, addresses(seed.Members()))
+}
+
+func TestLeaveOverLoopback(t *testing.T) {
+	is := is.New(t)
+
+	newNode := func(seeds ...string) (*actor.Engine, *cluster.Cluster) {
+		r, err := remote.New(remote.Config{ListenAddr: "127.0.0.1:0"})
+		is.NoErr(err)
+
+		engine := actor.NewEngine(actor.WithRemote(r))
+		t.Cleanup(engine.ShutdownAndWait)
+
+		if len(seeds) == 0 {
+			seeds = []string{engine.Address()}
+		}
+
+		c, err := cluster.New(engine, cluster.Config{Seeds: seeds, GossipInterval: 20 * time.Millisecond})
+		is.NoErr(err)
+
+		return engine, c
+	}
+
+	seedEngine, seed := newNode()
+	probe := actortest.NewProbe(t, seedEngine)
+	seedEngine.Subscribe(cluster.TopicMemberDown, probe.PID())
+
+	engine, c := newNode(seedEngine.Address())
+	leaving := actortest.NewProbe(t, engine)
+	engine.Subscribe(cluster.TopicMemberDown, leaving.PID())
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(seed.Members()) != 2 || len(c.Members()) != 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for members to converge")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	// leaving is announced right away
+	c.Leave()
+	is.Equal(engine.Address(), actortest.ExpectMsgType[cluster.MemberDown](leaving).Member.Address) // the engine that left sees itself go down
+	is.Equal(engine.Address(), actortest.ExpectMsgType[cluster.MemberDown](probe).Member.Address)
+	is.Equal([]string{seedEngine.Address()}, addresses(seed.Members()))
+}
+
+func TestClusterRequiresRemote(t *testing.T) {
+	is := is.New(t)
+
+	_, err := cluster.New(actor.NewEngine(), cluster.Config{})
+	is.Equal(cluster.ErrNoRemote, err)
+}
+
+func TestClusterInvalidConfig(t *testing.T) {
+	is := is.New(t)
+
+	_, err := cluster.New(actor.NewEngine(), cluster.Config{GossipInterval: time.Microsecond})
+	is.True(errors.Is(err, cluster.ErrInvalidConfig)) // too short to detect failures
+
+	_, err = cluster.New(actor.NewEngine(), cluster.Config{MinStdDeviation: -time.Millisecond})
+	is.True(errors.Is(err, cluster.ErrInvalidConfig))
+}
+
+// observer records the members of a cluster at the moment each event is published to it.
+type observer struct {
+	pid     actor.PID
+	cluster *cluster.Cluster
+	mu      sync.Mutex
+	seen    map[string][]string
+}
+
+func (o *observer) PID() actor.PID              { return o.pid }
+func (o *observer) Start()                      {}
+func (o *observer) Process(*actor.Envelope)     {}
+func (o *observer) Shutdown(wg *sync.WaitGroup) {}
+
+func (o *observer) Send(_ context.Context, _ actor.PID, msg any, _ actor.PID) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	switch msg := msg.(type) {
+	case cluster.MemberUp:
+		o.seen[msg.Member.Address] = addresses(o.cluster.Members())
+	case cluster.MemberDown:
+		o.seen[msg.Member.Address] = addresses(o.cluster.Members())
+	}
+}
+
+func TestEventsAfterUpdate(t *testing.T) {
+	is := is.New(t)
+
+	net := newNetwork()
+	d := actortest.NewDeterministic(actor.WithRemote(net.remote("a")))
+	t.Cleanup(d.Shutdown)
+	seed, err := cluster.New(d.Engine, cluster.Config{Seeds: []string{"a"}, GossipInterval: gossipInterval})
+	is.NoErr(err)
+
+	o := &observer{pid: actor.NewPID(d.Engine.Address(), "observer"), cluster: seed, seen: make(map[string][]string)}
+	d.Engine.SpawnProcessor(o)
+	d.Engine.Subscribe("cluster.member.*", o.PID())
+
+	other := d.NewEngine(actor.WithRemote(net.remote("b")))
+	t.Cleanup(other.Shutdown)
+	_, err = cluster.New(other.Engine, cluster.Config{Seeds: []string{"a"}, GossipInterval: gossipInterval})
+	is.NoErr(err)
+
+	d.Advance(gossipInterval)
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	is.Equal([]string{"a", "b"}, o.seen["b"]) // subscribers see the member that is up in the cluster
+}
@@ -0,0 +1,78 @@
+package cluster
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	detectorWindow = 100
+
+	// minStdDeviation keeps phi from dividing by zero when heartbeats are perfectly regular, in milliseconds.
+	minStdDeviation = 0.1
+)
+
+// phiDetector is a phi accrual failure detector.
+// Instead of a fixed timeout, it gives a suspicion level (phi) based on how late the next heartbeat is compared to the heartbeats seen so far.
+type phiDetector struct {
+	intervals []float64
+	last      time.Time
+	minStdDev float64
+	pause     float64
+}
+
+func newPhiDetector(now time.Time, expected, minStdDev, pause time.Duration) *phiDetector {
+	mean := milliseconds(expected)
+	std := mean / 4
+
+	return &phiDetector{
+		// seed with the expected interval, so the first few heartbeats don't cause false positives
+		intervals: []float64{mean - std, mean + std},
+		last:      now,
+		minStdDev: math.Max(milliseconds(minStdDev), minStdDeviation),
+		pause:     milliseconds(pause),
+	}
+}
+
+func (d *phiDetector) heartbeat(now time.Time) {
+	d.intervals = append(d.intervals, milliseconds(now.Sub(d.last)))
+	if len(d.intervals) > detectorWindow {
+		d.intervals = d.intervals[len(d.intervals)-detectorWindow:]
+	}
+	d.last = now
+}
+
+// reset starts over from now, used when a member becomes reachable again.
+func (d *phiDetector) reset(now time.Time) {
+	d.last = now
+}
+
+func (d *phiDetector) phi(now time.Time) float64 {
+	var sum float64
+	for _, i := range d.intervals {
+		sum += i
+	}
+	mean := sum / float64(len(d.intervals))
+
+	var variance float64
+	for _, i := range d.intervals {
+		variance += (i - mean) * (i - mean)
+	}
+	std := math.Max(math.Sqrt(variance/float64(len(d.intervals))), d.minStdDev)
+
+	mean += d.pause
+	elapsed := milliseconds(now.Sub(d.last))
+
+	// logistic approximation of the normal distribution cdf
+	y := (elapsed - mean) / std
+	e := math.Exp(-y * (1.5976 + 0.070566*y*y))
+	if elapsed > mean {
+		return -math.Log10(e / (1.0 + e))
+	}
+	return -math.Log10(1.0 - 1.0/(1.0+e))
+}
+
+// milliseconds without truncating, short gossip intervals would otherwise round down to 0.
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
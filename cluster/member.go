@@ -0,0 +1,54 @@
+package cluster
+
+import "sort"
+
+const (
+	// TopicMemberUp is published on each engine when a member joins, or is reachable again.
+	TopicMemberUp = "cluster.member.up"
+	// TopicMemberDown is published on each engine when a member leaves, or is detected as failed.
+	TopicMemberDown = "cluster.member.down"
+)
+
+type MemberStatus byte
+
+const (
+	MemberStatusUp MemberStatus = iota + 1
+	MemberStatusDown
+	MemberStatusLeft
+)
+
+func (s MemberStatus) String() string {
+	switch s {
+	case MemberStatusUp:
+		return "up"
+	case MemberStatusDown:
+		return "down"
+	case MemberStatusLeft:
+		return "left"
+	default:
+		return "unknown"
+	}
+}
+
+// Member is an engine that is part of the cluster.
+type Member struct {
+	Address   string
+	Status    MemberStatus
+	Heartbeat uint64
+	// Joined is when the member joined in unix nanoseconds, it tells engines restarted on the same address apart.
+	Joined int64
+}
+
+// MemberUp is published to TopicMemberUp.
+type MemberUp struct {
+	Member Member
+}
+
+// MemberDown is published to TopicMemberDown.
+type MemberDown struct {
+	Member Member
+}
+
+func sortMembers(members []Member) {
+	sort.Slice(members, func(i, j int) bool { return members[i].Address < members[j].Address })
+}
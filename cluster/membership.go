@@ -0,0 +1,259 @@
+package cluster
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"sort"
+
+	"github.com/renevo/actor"
+)
+
+type join struct {
+	Member Member
+}
+
+type gossip struct {
+	Members []Member
+}
+
+type leave struct {
+	Member Member
+}
+
+type gossipTick struct{}
+type leaveRequest struct{}
+
+type memberState struct {
+	Member
+	detector *phiDetector
+}
+
+// membership is the actor that gossips with the other members.
+type membership struct {
+	cluster  *Cluster
+	self     Member
+	members  map[string]*memberState
+	repeater actor.Repeater
+	started  bool
+	rand     *rand.Rand
+	events   []event
+}
+
+type event struct {
+	topic string
+	msg   any
+}
+
+func newMembership(c *Cluster) actor.Receiver {
+	// seeded by address, so peers are picked the same way every run
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(c.self.Address))
+
+	return &membership{
+		cluster: c,
+		self:    c.Self(),
+		members: make(map[string]*memberState),
+		rand:    rand.New(rand.NewSource(int64(h.Sum64()))),
+	}
+}
+
+func (m *membership) Receive(ctx *actor.Context) {
+	switch msg := ctx.Message().(type) {
+	case actor.Started:
+		if m.started {
+			return
+		}
+		m.started = true
+
+		m.publish(TopicMemberUp, MemberUp{Member: m.self})
+		m.commit(ctx)
+		m.join(ctx)
+		m.repeater = ctx.SendRepeat(ctx.PID(), gossipTick{}, m.cluster.config.GossipInterval)
+
+	case actor.Stopped:
+		if m.started {
+			m.repeater.Stop()
+			m.started = false
+		}
+
+	case gossipTick:
+		m.self.Heartbeat++
+		m.detect(ctx)
+		m.gossip(ctx)
+		m.commit(ctx)
+
+	case join:
+		m.merge(ctx, msg.Member)
+		ctx.Send(ctx.Context(), clusterPID(msg.Member.Address), m.digest())
+		m.commit(ctx)
+
+	case gossip:
+		for _, member := range msg.Members {
+			m.merge(ctx, member)
+		}
+		m.commit(ctx)
+
+	case leave:
+		if existing, ok := m.members[msg.Member.Address]; ok && existing.Joined == msg.Member.Joined && existing.Status != MemberStatusLeft {
+			wasUp := existing.Status == MemberStatusUp
+			existing.Status = MemberStatusLeft
+			if wasUp {
+				m.publish(TopicMemberDown, MemberDown{Member: existing.Member})
+			}
+		}
+		m.commit(ctx)
+
+	case leaveRequest:
+		m.self.Status = MemberStatusLeft
+		for _, member := range m.members {
+			if member.Status == MemberStatusUp {
+				ctx.Send(ctx.Context(), clusterPID(member.Address), leave{Member: m.self})
+			}
+		}
+		m.publish(TopicMemberDown, MemberDown{Member: m.self})
+		m.commit(ctx)
+	}
+}
+
+// publish an event once the cluster has been updated, so subscribers never see an event before the members it is about.
+func (m *membership) publish(topic string, msg any) {
+	m.events = append(m.events, event{topic: topic, msg: msg})
+}
+
+// commit the membership to the cluster, and publish any events that came from it.
+func (m *membership) commit(ctx *actor.Context) {
+	m.cluster.update(m.self, m.members)
+
+	for _, e := range m.events {
+		ctx.Engine().Publish(ctx.Context(), e.topic, e.msg)
+	}
+	m.events = nil
+}
+
+func (m *membership) join(ctx *actor.Context) {
+	for _, seed := range m.cluster.config.Seeds {
+		if seed == m.self.Address {
+			continue
+		}
+		ctx.Send(ctx.Context(), clusterPID(seed), join{Member: m.self})
+	}
+}
+
+func (m *membership) digest() gossip {
+	members := make([]Member, 0, len(m.members)+1)
+	members = append(members, m.self)
+	for _, member := range m.members {
+		members = append(members, member.Member)
+	}
+	return gossip{Members: members}
+}
+
+func (m *membership) gossip(ctx *actor.Context) {
+	if m.self.Status != MemberStatusUp {
+		return
+	}
+
+	var peers []string
+	for address, member := range m.members {
+		if member.Status == MemberStatusUp {
+			peers = append(peers, address)
+		}
+	}
+
+	// nobody to talk to, keep trying the seeds in case they came up after us
+	if len(peers) == 0 {
+		m.join(ctx)
+		return
+	}
+
+	// map order is random, so sort before shuffling to keep the seeded shuffle repeatable
+	sort.Strings(peers)
+	m.rand.Shuffle(len(peers), func(i, j int) { peers[i], peers[j] = peers[j], peers[i] })
+	if len(peers) > m.cluster.config.GossipFanout {
+		peers = peers[:m.cluster.config.GossipFanout]
+	}
+
+	digest := m.digest()
+	for _, address := range peers {
+		ctx.Send(ctx.Context(), clusterPID(address), digest)
+	}
+}
+
+func (m *membership) detect(ctx *actor.Context) {
+	now := ctx.Engine().Clock().Now()
+	for _, member := range m.members {
+		if member.Status != MemberStatusUp {
+			continue
+		}
+
+		if phi := member.detector.phi(now); phi > m.cluster.config.PhiThreshold {
+			ctx.Log().Warn("Cluster member unreachable.", "member", member.Address, "phi", phi)
+			member.Status = MemberStatusDown
+			m.publish(TopicMemberDown, MemberDown{Member: member.Member})
+		}
+	}
+}
+
+func (m *membership) merge(ctx *actor.Context, incoming Member) {
+	if incoming.Address == m.self.Address {
+		return
+	}
+
+	now := ctx.Engine().Clock().Now()
+	config := m.cluster.config
+	existing, ok := m.members[incoming.Address]
+
+	// a new member, or an engine that was restarted on the same address
+	if !ok || incoming.Joined > existing.Joined {
+		if ok && existing.Status == MemberStatusUp {
+			existing.Status = MemberStatusDown
+			m.publish(TopicMemberDown, MemberDown{Member: existing.Member})
+		}
+
+		// only members that are up are tracked, anything else we may not have been able to see for ourselves
+		state := &memberState{
+			Member:   incoming,
+			detector: newPhiDetector(now, config.GossipInterval, config.MinStdDeviation, config.AcceptablePause),
+		}
+		if incoming.Status != MemberStatusUp && incoming.Status != MemberStatusLeft {
+			state.Status = MemberStatusDown
+		}
+		m.members[incoming.Address] = state
+
+		if state.Status == MemberStatusUp {
+			ctx.Log().Info("Cluster member up.", "member", incoming.Address)
+			m.publish(TopicMemberUp, MemberUp{Member: state.Member})
+		}
+		return
+	}
+
+	// old news about an engine that has since restarted, or a member that is gone for good
+	if incoming.Joined < existing.Joined || existing.Status == MemberStatusLeft {
+		return
+	}
+
+	if incoming.Status == MemberStatusLeft {
+		wasUp := existing.Status == MemberStatusUp
+		existing.Status = MemberStatusLeft
+		if wasUp {
+			m.publish(TopicMemberDown, MemberDown{Member: existing.Member})
+		}
+		return
+	}
+
+	// down is decided by each engine for itself, so only heartbeats matter from here
+	if incoming.Heartbeat <= existing.Heartbeat {
+		return
+	}
+	existing.Heartbeat = incoming.Heartbeat
+
+	if existing.Status == MemberStatusDown {
+		ctx.Log().Info("Cluster member reachable again.", "member", incoming.Address)
+		existing.Status = MemberStatusUp
+		existing.detector.reset(now)
+		m.publish(TopicMemberUp, MemberUp{Member: existing.Member})
+		return
+	}
+
+	existing.detector.heartbeat(now)
+}
@@ -0,0 +1,135 @@
+package cluster
+
+import (
+	"reflect"
+
+	"github.com/renevo/actor"
+	"github.com/renevo/actor/remote"
+)
+
+// maxHandoffHops is how many times a message can be handed off before it is deadlettered, members that disagree on the owner would otherwise pass it back and forth.
+const maxHandoffHops = 8
+
+func init() {
+	remote.Register(handedOff{})
+}
+
+// handedOff wraps a message handed off to the member that owns the grain.
+type handedOff struct {
+	Message any
+	Hops    int
+}
+
+// RegisterKind registers a kind of grain that is placed across the cluster, it must be registered on every member with the same name.
+// Grains are owned by a single member picked with a consistent hash of their identity, so a change in membership only moves the grains of the members that changed.
+//
+// A grain that is no longer owned by this member hands off any messages it receives to the new owner and stops, its state isn't moved with it.
+func (c *Cluster) RegisterKind(name string, producer actor.Producer, opts ...actor.Option) {
+	c.engine.RegisterKind(name, func() actor.Receiver {
+		return &placed{cluster: c, receiver: producer()}
+	}, opts...)
+}
+
+// GetGrain returns the PID of the grain with the given kind and id on the member that owns it.
+func (c *Cluster) GetGrain(kind string, id string) actor.PID {
+	pid := c.engine.GetGrain(kind, id)
+	pid.Address = c.owner(pid)
+	return pid
+}
+
+// Owner returns the address of the member that owns the grain with the given kind and id.
+func (c *Cluster) Owner(kind string, id string) string {
+	return c.owner(c.engine.GetGrain(kind, id))
+}
+
+func (c *Cluster) owner(pid actor.PID) string {
+	owner, ok := c.ring.Get(pid.ID)
+	if !ok {
+		// nobody is up (we may be leaving), so keep it where it is
+		return c.engine.Address()
+	}
+	return owner
+}
+
+// syncRing brings the ring in line with the members that are up, must be called while holding the lock.
+func (c *Cluster) syncRing() {
+	up := make(map[string]bool, len(c.members))
+	for address, m := range c.members {
+		if m.Status == MemberStatusUp {
+			up[address] = true
+		}
+	}
+
+	for _, address := range c.ring.Members() {
+		if !up[address] {
+			c.ring.Remove(address)
+		}
+	}
+
+	for address := range up {
+		c.ring.Add(address)
+	}
+}
+
+// placed wraps the receiver of a cluster grain, so messages for a grain that has moved are handed off to the new owner.
+type placed struct {
+	cluster  *Cluster
+	receiver actor.Receiver
+	started  bool
+	moved    bool
+}
+
+func (p *placed) Receive(ctx *actor.Context) {
+	hops := 0
+	if msg, ok := ctx.Message().(handedOff); ok {
+		hops = msg.Hops
+		ctx.WithMessage(msg.Message)
+	}
+
+	switch ctx.Message().(type) {
+	case actor.Initialized, actor.Started:
+		// activated by a message for a grain this member doesn't own, so don't bother starting it
+		if !p.started && !p.owned(ctx) {
+			return
+		}
+		p.started = true
+		p.receiver.Receive(ctx)
+		return
+
+	case actor.Stopped, actor.Restarting, actor.Restarted:
+		if p.started {
+			p.receiver.Receive(ctx)
+		}
+		return
+	}
+
+	if !p.owned(ctx) {
+		p.handoff(ctx, hops)
+		return
+	}
+
+	p.receiver.Receive(ctx)
+}
+
+func (p *placed) owned(ctx *actor.Context) bool {
+	return p.cluster.owner(ctx.PID()) == ctx.Engine().Address()
+}
+
+func (p *placed) handoff(ctx *actor.Context, hops int) {
+	to := ctx.PID()
+	to.Address = p.cluster.owner(to)
+
+	if hops >= maxHandoffHops {
+		ctx.Log().Warn("Grain message handed off too many times, members disagree on the owner.", "owner", to.Address, "hops", hops, "type", reflect.TypeOf(ctx.Message()))
+		ctx.Engine().DeadLetter(ctx.Context(), ctx.PID(), ctx.Message(), ctx.Sender())
+	} else {
+		ctx.Log().Debug("Handing off grain message.", "owner", to.Address, "type", reflect.TypeOf(ctx.Message()))
+		ctx.Engine().Relay(ctx.Context(), to, handedOff{Message: ctx.Message(), Hops: hops + 1}, ctx.Sender())
+	}
+
+	// stop once, anything else already in the inbox is handed off on the way
+	if !p.moved {
+		p.moved = true
+		ctx.Engine().Poison(ctx.PID(), nil)
+	}
+}
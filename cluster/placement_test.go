@@ -0,0 +1,178 @@
+package cluster_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/renevo/actor"
+	"github.com/renevo/actor/actortest"
+	"github.com/renevo/actor/cluster"
+)
+
+type increment struct {
+	Reply actor.PID
+}
+
+type counted struct {
+	Count   int
+	Address string
+}
+
+type counter struct {
+	count int
+}
+
+func (c *counter) Receive(ctx *actor.Context) {
+	if msg, ok := ctx.Message().(increment); ok {
+		c.count++
+		ctx.Send(ctx.Context(), msg.Reply, counted{Count: c.count, Address: ctx.Engine().Address()})
+	}
+}
+
+func TestPlacement(t *testing.T) {
+	is := is.New(t)
+
+	net := newNetwork()
+	var d *actortest.Deterministic
+	nodes := map[string]*cluster.Cluster{}
+
+	join := func(address string) {
+		var engine *actortest.Deterministic
+		if d == nil {
+			d = actortest.NewDeterministic(actor.WithRemote(net.remote(address)))
+			engine = d
+		} else {
+			engine = d.NewEngine(actor.WithRemote(net.remote(address)))
+		}
+		t.Cleanup(engine.Shutdown)
+
+		c, err := cluster.New(engine.Engine, cluster.Config{Seeds: []string{"a"}, GossipInterval: gossipInterval})
+		is.NoErr(err)
+		c.RegisterKind("counter", func() actor.Receiver { return &counter{} })
+		nodes[address] = c
+	}
+
+	for _, address := range []string{"a", "b", "c"} {
+		join(address)
+	}
+	for i := 0; i < 3; i++ {
+		d.Advance(gossipInterval)
+	}
+
+	probe := actortest.NewProbe(t, d.Engine)
+
+	// every member agrees on where a grain lives
+	owners := map[string]string{}
+	for i := 0; i < 100; i++ {
+		id := strconv.Itoa(i)
+		owners[id] = nodes["a"].Owner("counter", id)
+		is.Equal(owners[id], nodes["b"].Owner("counter", id))
+		is.Equal(owners[id], nodes["c"].Owner("counter", id))
+	}
+
+	// sent from any member, messages end up on the owner
+	pid := nodes["b"].GetGrain("counter", "0")
+	is.Equal(owners["0"], pid.Address)
+	d.Engine.Send(context.Background(), nodes["a"].GetGrain("counter", "0"), increment{Reply: probe.PID()})
+	d.Engine.Send(context.Background(), nodes["c"].GetGrain("counter", "0"), increment{Reply: probe.PID()})
+	d.RunUntilIdle()
+	probe.ExpectMsg(counted{Count: 1, Address: owners["0"]})
+	probe.ExpectMsg(counted{Count: 2, Address: owners["0"]})
+
+	// activate every grain, so there are grains running that will have to move
+	for id := range owners {
+		d.Engine.Send(context.Background(), nodes["a"].GetGrain("counter", id), increment{Reply: probe.PID()})
+	}
+	d.RunUntilIdle()
+	for range owners {
+		probe.Receive()
+	}
+
+	// a new member only takes grains, nothing moves between the existing members
+	join("d")
+	for i := 0; i < 3; i++ {
+		d.Advance(gossipInterval)
+	}
+
+	moved := ""
+	for id, owner := range owners {
+		now := nodes["a"].Owner("counter", id)
+		if now != owner {
+			is.Equal("d", now)
+			moved = id
+		}
+	}
+	is.True(moved != "") // some grains should have moved to the new member
+
+	// messages sent to the old owner are handed off to the new one, which starts the grain over
+	old := actor.NewPID(owners[moved], "counter", moved)
+	d.Engine.Send(context.Background(), old, increment{Reply: probe.PID()})
+	d.Engine.Send(context.Background(), old, increment{Reply: probe.PID()})
+	d.RunUntilIdle()
+	probe.ExpectMsg(counted{Count: 1, Address: "d"})
+	probe.ExpectMsg(counted{Count: 2, Address: "d"})
+}
+
+func TestHandoffHops(t *testing.T) {
+	is := is.New(t)
+
+	net := newNetwork()
+	d := actortest.NewDeterministic(actor.WithRemote(net.remote("a")))
+	t.Cleanup(d.Shutdown)
+
+	// two clusters that each see different members, like members that disagree during a partition
+	received := 0
+	nodes := map[string]*cluster.Cluster{}
+	for _, address := range []string{"a", "b", "c", "d"} {
+		engine := d
+		if address != "a" {
+			engine = d.NewEngine(actor.WithRemote(net.remote(address)))
+			t.Cleanup(engine.Shutdown)
+		}
+
+		seed := "a"
+		if address == "c" || address == "d" {
+			seed = "c"
+		}
+
+		c, err := cluster.New(engine.Engine, cluster.Config{Seeds: []string{seed}, GossipInterval: gossipInterval})
+		is.NoErr(err)
+		c.RegisterKind("counter", func() actor.Receiver {
+			return actor.ReceiverFunc(func(ctx *actor.Context) {
+				if _, ok := ctx.Message().(increment); ok {
+					received++
+				}
+			})
+		})
+		nodes[address] = c
+	}
+	for i := 0; i < 3; i++ {
+		d.Advance(gossipInterval)
+	}
+
+	id := 0
+	for nodes["a"].Owner("counter", strconv.Itoa(id)) != "b" || nodes["c"].Owner("counter", strconv.Itoa(id)) != "d" {
+		id++
+	}
+
+	// a thinks b owns the grain and c thinks d does, but the network takes them to each other
+	net.mu.Lock()
+	net.route = func(to actor.PID) actor.PID {
+		if to.ID == "counter."+strconv.Itoa(id) {
+			switch to.Address {
+			case "b":
+				to.Address = "c"
+			case "d":
+				to.Address = "a"
+			}
+		}
+		return to
+	}
+	net.mu.Unlock()
+
+	d.Engine.Send(context.Background(), nodes["a"].GetGrain("counter", strconv.Itoa(id)), increment{})
+	d.RunUntilIdle()
+	is.Equal(0, received) // handed off until it was deadlettered
+}
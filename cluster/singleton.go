@@ -0,0 +1,177 @@
+package cluster
+
+import (
+	"context"
+
+	"github.com/renevo/actor"
+	"github.com/renevo/actor/remote"
+)
+
+const (
+	singletonName         = "singleton"
+	singletonInstanceName = "instance"
+	singletonBufferSize   = 1024
+)
+
+func init() {
+	remote.Register(singletonStarted{})
+	remote.Register(singletonQuery{})
+}
+
+// singletonStarted is sent by the member running a singleton to the managers on the other members.
+type singletonStarted struct {
+	Address string
+}
+
+// singletonQuery asks the member that should be running a singleton to announce itself.
+type singletonQuery struct{}
+
+// Singleton runs the actor on the oldest member of the cluster, it must be registered on every member with the same name.
+// The returned PID is a local proxy that forwards messages to wherever the singleton is running, buffering them while it is being started or moved.
+//
+// When the oldest member leaves or fails, the singleton is started again on the next oldest member, its state isn't moved with it.
+// There is only ever one singleton once all members agree on the membership, while they don't (such as during a network partition) there may be more.
+func (c *Cluster) Singleton(name string, producer actor.Producer, opts ...actor.Option) actor.PID {
+	return c.engine.Spawn(&singletonManager{
+		cluster:  c,
+		producer: producer,
+		opts:     opts,
+	}, singletonName, actor.WithTags(name))
+}
+
+// oldest returns the address of the member that has been up the longest, false is returned until this member has joined the cluster.
+func (c *Cluster) oldest() (string, bool) {
+	c.mu.RLock()
+	joined := c.joined
+	c.mu.RUnlock()
+
+	members := c.Members()
+	if !joined || len(members) == 0 {
+		return "", false
+	}
+
+	oldest := members[0]
+	for _, m := range members[1:] {
+		if m.Joined < oldest.Joined {
+			oldest = m
+		}
+	}
+
+	return oldest.Address, true
+}
+
+type buffered struct {
+	ctx  context.Context
+	msg  any
+	from actor.PID
+}
+
+// singletonManager runs the singleton when this is the oldest member, and is the proxy to it on every member.
+type singletonManager struct {
+	cluster  *Cluster
+	producer actor.Producer
+	opts     []actor.Option
+	instance actor.PID
+	running  bool
+	owner    string
+	buffer   []buffered
+}
+
+func (m *singletonManager) Receive(ctx *actor.Context) {
+	switch msg := ctx.Message().(type) {
+	case actor.Initialized, actor.Stopped, actor.Restarting, actor.Restarted:
+
+	case actor.Started:
+		ctx.Subscribe(TopicMemberUp)
+		ctx.Subscribe(TopicMemberDown)
+		m.evaluate(ctx)
+
+	case MemberUp:
+		m.evaluate(ctx)
+		// new members need to know where the singleton is
+		if m.running && msg.Member.Address != ctx.Engine().Address() {
+			ctx.Send(ctx.Context(), actor.NewPID(msg.Member.Address, ctx.PID().ID), singletonStarted{Address: ctx.Engine().Address()})
+		}
+
+	case MemberDown:
+		m.evaluate(ctx)
+
+	case singletonQuery:
+		if m.running {
+			ctx.Send(ctx.Context(), ctx.Sender(), singletonStarted{Address: ctx.Engine().Address()})
+		}
+
+	case singletonStarted:
+		if oldest, ok := m.cluster.oldest(); ok && oldest == msg.Address {
+			m.owner = msg.Address
+			m.flush(ctx)
+		}
+
+	default:
+		if m.owner == "" {
+			m.hold(ctx)
+			return
+		}
+
+		ctx.Engine().Relay(ctx.Context(), m.instancePID(m.owner, ctx), ctx.Message(), ctx.Sender())
+	}
+}
+
+// evaluate where the singleton should be running, starting or stopping it here as needed.
+func (m *singletonManager) evaluate(ctx *actor.Context) {
+	self := ctx.Engine().Address()
+	oldest, ok := m.cluster.oldest()
+
+	if ok && oldest == self {
+		if !m.running {
+			ctx.Log().Info("Starting cluster singleton.")
+			m.instance = ctx.Spawn(m.producer(), singletonInstanceName, m.opts...)
+			m.running = true
+
+			for _, member := range m.cluster.Members() {
+				if member.Address != self {
+					ctx.Send(ctx.Context(), actor.NewPID(member.Address, ctx.PID().ID), singletonStarted{Address: self})
+				}
+			}
+		}
+
+		m.owner = self
+		m.flush(ctx)
+		return
+	}
+
+	if m.running {
+		ctx.Log().Info("Stopping cluster singleton, it is moving to another member.", "owner", oldest)
+		ctx.Engine().Poison(m.instance, nil)
+		m.running = false
+	}
+
+	// hold on to messages until the new owner says it is running
+	if m.owner != oldest {
+		m.owner = ""
+		if ok {
+			ctx.Send(ctx.Context(), actor.NewPID(oldest, ctx.PID().ID), singletonQuery{})
+		}
+	}
+}
+
+func (m *singletonManager) hold(ctx *actor.Context) {
+	if len(m.buffer) >= singletonBufferSize {
+		ctx.Log().Warn("Cluster singleton buffer full, dropping message.", "from", ctx.Sender())
+		return
+	}
+
+	m.buffer = append(m.buffer, buffered{ctx: ctx.Context(), msg: ctx.Message(), from: ctx.Sender()})
+}
+
+func (m *singletonManager) flush(ctx *actor.Context) {
+	to := m.instancePID(m.owner, ctx)
+	for _, b := range m.buffer {
+		ctx.Engine().Relay(b.ctx, to, b.msg, b.from)
+	}
+	m.buffer = nil
+}
+
+func (m *singletonManager) instancePID(address string, ctx *actor.Context) actor.PID {
+	return actor.NewPID(address, ctx.PID().ID, singletonInstanceName)
+}
@@ -0,0 +1,127 @@
+package cluster_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/renevo/actor"
+	"github.com/renevo/actor/actortest"
+	"github.com/renevo/actor/cluster"
+)
+
+func TestSingleton(t *testing.T) {
+	is := is.New(t)
+
+	net := newNetwork()
+	var d *actortest.Deterministic
+	nodes := map[string]*cluster.Cluster{}
+	engines := map[string]*actortest.Deterministic{}
+	started := map[string]int{}
+
+	for _, address := range []string{"a", "b", "c"} {
+		var engine *actortest.Deterministic
+		if d == nil {
+			d = actortest.NewDeterministic(actor.WithRemote(net.remote(address)))
+			engine = d
+		} else {
+			engine = d.NewEngine(actor.WithRemote(net.remote(address)))
+		}
+		t.Cleanup(engine.Shutdown)
+
+		c, err := cluster.New(engine.Engine, cluster.Config{Seeds: []string{"a"}, GossipInterval: gossipInterval})
+		is.NoErr(err)
+		nodes[address] = c
+		engines[address] = engine
+
+		address := address
+		c.Singleton("counter", func() actor.Receiver {
+			started[address]++
+			return &counter{}
+		})
+
+		// members join one after the other, so a is the oldest
+		d.Advance(gossipInterval)
+	}
+	for i := 0; i < 3; i++ {
+		d.Advance(gossipInterval)
+	}
+
+	is.Equal(map[string]int{"a": 1}, started) // only the oldest member runs the singleton
+
+	// the proxy on any member forwards to the singleton
+	probe := actortest.NewProbe(t, engines["c"].Engine)
+	proxy := engines["c"].Engine.GetPID("singleton", "counter")
+	engines["c"].Engine.Send(context.Background(), proxy, increment{Reply: probe.PID()})
+	engines["c"].Engine.Send(context.Background(), proxy, increment{Reply: probe.PID()})
+	d.RunUntilIdle()
+	probe.ExpectMsg(counted{Count: 1, Address: "a"})
+	probe.ExpectMsg(counted{Count: 2, Address: "a"})
+
+	// when the oldest member leaves, the singleton moves to the next oldest
+	leave(d, nodes["a"])
+	for i := 0; i < 3; i++ {
+		d.Advance(gossipInterval)
+	}
+
+	is.Equal(map[string]int{"a": 1, "b": 1}, started)
+	engines["c"].Engine.Send(context.Background(), proxy, increment{Reply: probe.PID()})
+	d.RunUntilIdle()
+	probe.ExpectMsg(counted{Count: 1, Address: "b"})
+}
+
+func TestSingletonBuffersUntilStarted(t *testing.T) {
+	net := newNetwork()
+
+	a := actortest.NewDeterministic(actor.WithRemote(net.remote("a")))
+	t.Cleanup(a.Shutdown)
+	b := a.NewEngine(actor.WithRemote(net.remote("b")))
+	t.Cleanup(b.Shutdown)
+
+	ca, err := cluster.New(a.Engine, cluster.Config{Seeds: []string{"a"}, GossipInterval: gossipInterval})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.Advance(gossipInterval)
+
+	cb, err := cluster.New(b.Engine, cluster.Config{Seeds: []string{"a"}, GossipInterval: gossipInterval})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		a.Advance(gossipInterval)
+	}
+
+	probe := actortest.NewProbe(t, b.Engine)
+
+	// the proxy on b is up before the singleton has been started on a
+	proxy := cb.Singleton("counter", func() actor.Receiver { return &counter{} })
+	b.RunUntilIdle()
+	b.Engine.Send(context.Background(), proxy, increment{Reply: probe.PID()})
+	b.RunUntilIdle()
+	probe.ExpectNoMsg(0)
+
+	ca.Singleton("counter", func() actor.Receiver { return &counter{} })
+	a.RunUntilIdle()
+	probe.ExpectMsg(counted{Count: 1, Address: "a"})
+}
+
+// leave the cluster while running the engines, as leaving waits on the cluster actor to stop.
+func leave(d *actortest.Deterministic, c *cluster.Cluster) {
+	done := make(chan struct{})
+	go func() {
+		c.Leave()
+		close(done)
+	}()
+
+	for {
+		d.RunUntilIdle()
+
+		select {
+		case <-done:
+			return
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
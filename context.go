@@ -3,6 +3,8 @@ package actor
 import (
 	"context"
 	"log/slog"
+	"sort"
+	"sync/atomic"
 )
 
 type Context struct {
@@ -14,15 +16,21 @@ type Context struct {
 	message       any
 	ctx           context.Context
 	parentContext *Context
-	children      *safemap[string, PID]
+	children      *safemap[string, child]
+	childSeq      atomic.Uint64
 	logger        *slog.Logger
 }
 
+type child struct {
+	pid PID
+	seq uint64
+}
+
 func newContext(e *Engine, pid PID) *Context {
 	return &Context{
 		engine:   e,
 		pid:      pid,
-		children: newMap[string, PID](),
+		children: newMap[string, child](),
 		logger:   e.options.Logger.With("actor", pid.String()),
 	}
 }
@@ -41,6 +49,16 @@ func (c *Context) Forward(to PID) {
 	c.engine.send(c.ctx, to, c.message, c.pid)
 }
 
+// Watch another actor, this actor will receive a Terminated message once it has stopped.
+func (c *Context) Watch(pid PID) {
+	c.engine.Watch(pid, c.pid)
+}
+
+// Unwatch another actor.
+func (c *Context) Unwatch(pid PID) {
+	c.engine.Unwatch(pid, c.pid)
+}
+
 func (c *Context) GetPID(name string, tags ...string) PID {
 	return c.engine.GetPID(name, tags...)
 }
@@ -70,16 +88,22 @@ func (c *Context) Parent() PID {
 }
 
 func (c *Context) Child(id string) (PID, bool) {
-	return c.children.Get(id)
+	ch, ok := c.children.Get(id)
+	return ch.pid, ok
 }
 
+// Children returns the PIDs of the children of this actor, in the order they were spawned.
 func (c *Context) Children() []PID {
-	pids := make([]PID, c.children.Len())
-	i := 0
-	c.children.ForEach(func(_ string, child PID) {
-		pids[i] = child
-		i++
+	var children []child
+	c.children.ForEach(func(_ string, ch child) {
+		children = append(children, ch)
 	})
+	sort.Slice(children, func(i, j int) bool { return children[i].seq < children[j].seq })
+
+	pids := make([]PID, len(children))
+	for i, ch := range children {
+		pids[i] = ch.pid
+	}
 	return pids
 }
 
@@ -99,10 +123,17 @@ func (c *Context) Spawn(receiver Receiver, name string, opts ...Option) PID {
 	}
 	proc := newProcessor(c.engine, options)
 	proc.context.parentContext = c
-	pid := c.engine.SpawnProcessor(proc)
-	c.children.Set(pid.ID, pid)
+	pid, spawned, err := c.engine.spawn(proc, options.SpawnPolicy)
+	if err != nil {
+		c.logger.Warn("Attempt to spawn duplicate child actor.", "pid", pid, "err", err)
+		return pid
+	}
+	if !spawned {
+		return pid
+	}
+	c.children.Set(pid.ID, child{pid: pid, seq: c.childSeq.Add(1)})
 
-	return proc.PID()
+	return pid
 }
 
 func (c *Context) SpawnFunc(fn ReceiverFunc, name string, opts ...Option) PID {
@@ -118,6 +149,12 @@ func (c *Context) WithContext(ctx context.Context) *Context {
 	return c
 }
 
+// WithMessage replaces the message being processed, so middleware can unwrap a message before it is received.
+func (c *Context) WithMessage(msg any) *Context {
+	c.message = msg
+	return c
+}
+
 func (c *Context) Log() *slog.Logger {
 	return c.logger
 }
@@ -127,3 +127,78 @@ func TestSpawnChild(t *testing.T) {
 
 	is.Equal(deadletter, engine.GetPID("TestSpawnChild", "child"))
 }
+
+func TestChildShutdownOrder(t *testing.T) {
+	is := is.New(t)
+
+	engine := actor.NewEngine()
+
+	var mu sync.Mutex
+	var stopped []string
+	record := func(ctx *actor.Context) {
+		if _, ok := ctx.Message().(actor.Stopped); ok {
+			mu.Lock()
+			defer mu.Unlock()
+			stopped = append(stopped, ctx.PID().ID)
+		}
+	}
+
+	started := &sync.WaitGroup{}
+	started.Add(4)
+	child := func(ctx *actor.Context) {
+		if _, ok := ctx.Message().(actor.Started); ok {
+			started.Done()
+		}
+		record(ctx)
+	}
+
+	pid := engine.SpawnFunc(func(ctx *actor.Context) {
+		if _, ok := ctx.Message().(actor.Started); ok {
+			ctx.SpawnFunc(child, "1")
+			ctx.SpawnFunc(child, "2")
+			ctx.SpawnFunc(func(ctx *actor.Context) {
+				if _, ok := ctx.Message().(actor.Started); ok {
+					ctx.SpawnFunc(child, "grandchild")
+				}
+				child(ctx)
+			}, "3")
+		}
+		record(ctx)
+	}, "TestChildShutdownOrder")
+	started.Wait()
+
+	// children are stopped the last spawned first, and their parent only once they all have
+	<-engine.Poison(pid, nil)
+	is.Equal([]string{
+		"TestChildShutdownOrder.3.grandchild",
+		"TestChildShutdownOrder.3",
+		"TestChildShutdownOrder.2",
+		"TestChildShutdownOrder.1",
+		"TestChildShutdownOrder",
+	}, stopped)
+
+	engine.ShutdownAndWait()
+}
+
+func TestSpawnExistingChild(t *testing.T) {
+	is := is.New(t)
+
+	engine := actor.NewEngine()
+	t.Cleanup(engine.ShutdownAndWait)
+
+	children := make(chan []actor.PID, 1)
+	engine.SpawnFunc(func(ctx *actor.Context) {
+		if _, ok := ctx.Message().(actor.Started); ok {
+			first := ctx.SpawnFunc(func(*actor.Context) {}, "1")
+			ctx.SpawnFunc(func(*actor.Context) {}, "2")
+			existing := ctx.SpawnFunc(func(*actor.Context) {}, "1", actor.WithSpawnPolicy(actor.SpawnPolicyExisting))
+			is.Equal(first, existing)
+			children <- ctx.Children()
+		}
+	}, "TestSpawnExistingChild")
+
+	pids := <-children
+	is.Equal(len(pids), 2)
+	is.Equal(pids[0].ID, "TestSpawnExistingChild.1") // the existing child keeps its place
+	is.Equal(pids[1].ID, "TestSpawnExistingChild.2")
+}
@@ -0,0 +1,118 @@
+package actor
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	ErrDispatcherStopped = errors.New("dispatcher stopped")
+)
+
+// Dispatcher runs the processing of inboxes that have pending messages.
+// An inbox is only ever scheduled once at a time, so scheduled functions for the same actor will never run concurrently.
+type Dispatcher interface {
+	Schedule(fn func())
+}
+
+// GoroutineDispatcher runs every scheduled inbox on its own goroutine, which keeps running until the inbox is empty or its throughput is reached.
+// This is the default Dispatcher.
+type GoroutineDispatcher struct{}
+
+func (GoroutineDispatcher) Schedule(fn func()) {
+	go fn()
+}
+
+// PoolDispatcher runs scheduled inboxes on a fixed number of worker goroutines shared by all actors using it.
+// Idle actors don't hold on to a goroutine, which makes it suitable for large numbers of mostly idle actors.
+// Actors that block (e.g. Request) hold on to a worker while they wait, so the pool should be sized for that.
+//
+// A sender blocked on a full inbox would also hold on to a worker, and could deadlock the pool when every worker is doing the same,
+// so messages sent to a full inbox of an actor on a PoolDispatcher are not waited on, and go to the deadletter instead.
+// Messages sent once the dispatcher has been stopped go to the deadletter too, as they would never be processed.
+type PoolDispatcher struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	queue   []func()
+	stopped atomic.Bool
+	wg      sync.WaitGroup
+}
+
+// NewPoolDispatcher creates a PoolDispatcher and starts the given number of workers.
+func NewPoolDispatcher(workers int) *PoolDispatcher {
+	if workers <= 0 {
+		workers = 1
+	}
+
+	d := &PoolDispatcher{}
+	d.cond = sync.NewCond(&d.mu)
+
+	d.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go d.work()
+	}
+
+	return d
+}
+
+// Schedule queues fn to run on a worker, fn is dropped if the dispatcher has been stopped.
+func (d *PoolDispatcher) Schedule(fn func()) {
+	d.trySchedule(fn)
+}
+
+// trySchedule queues fn to run on a worker, returning false when it won't as the dispatcher has been stopped.
+func (d *PoolDispatcher) trySchedule(fn func()) bool {
+	d.mu.Lock()
+	if d.stopped.Load() {
+		d.mu.Unlock()
+		return false
+	}
+	d.queue = append(d.queue, fn)
+	d.mu.Unlock()
+
+	d.cond.Signal()
+	return true
+}
+
+// Stop the workers once everything that was scheduled has run.
+// All engines using the dispatcher must be shutdown first.
+func (d *PoolDispatcher) Stop() {
+	d.mu.Lock()
+	d.stopped.Store(true)
+	d.mu.Unlock()
+
+	d.cond.Broadcast()
+	d.wg.Wait()
+}
+
+func (d *PoolDispatcher) rejectsFullInbox() bool {
+	return true
+}
+
+func (d *PoolDispatcher) isStopped() bool {
+	return d.stopped.Load()
+}
+
+func (d *PoolDispatcher) work() {
+	defer d.wg.Done()
+
+	for {
+		d.mu.Lock()
+		for len(d.queue) == 0 && !d.stopped.Load() {
+			d.cond.Wait()
+		}
+
+		if len(d.queue) == 0 {
+			d.mu.Unlock()
+			return
+		}
+
+		fn := d.queue[0]
+		d.queue[0] = nil
+		d.queue = d.queue[1:]
+		d.mu.Unlock()
+
+		fn()
+	}
+}
@@ -0,0 +1,180 @@
+package actor_test
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/renevo/actor"
+	"github.com/renevo/actor/actortest"
+)
+
+func TestPoolDispatcher(t *testing.T) {
+	is := is.New(t)
+
+	dispatcher := actor.NewPoolDispatcher(4)
+	defer dispatcher.Stop()
+
+	engine := actor.NewEngine(actor.WithDispatcher(dispatcher), actor.WithThroughput(10))
+	wg := &sync.WaitGroup{}
+
+	const actors = 1000
+	const messages = 20
+	outOfOrder := make([]bool, actors)
+
+	for i := 0; i < actors; i++ {
+		i := i
+		last := -1
+		pid := engine.SpawnFunc(func(ctx *actor.Context) {
+			if n, ok := ctx.Message().(int); ok {
+				if n != last+1 {
+					outOfOrder[i] = true
+				}
+				last = n
+				wg.Done()
+			}
+		}, "TestPoolDispatcher", actor.WithTags(strconv.Itoa(i)))
+
+		wg.Add(messages)
+		for n := 0; n < messages; n++ {
+			engine.Send(context.Background(), pid, n)
+		}
+	}
+
+	wg.Wait()
+	for i := range outOfOrder {
+		is.True(!outOfOrder[i]) // messages should be processed in order per actor
+	}
+
+	engine.ShutdownAndWait()
+}
+
+func TestThroughputFairness(t *testing.T) {
+	is := is.New(t)
+
+	dispatcher := actortest.NewManualDispatcher()
+	engine := actor.NewEngine(actor.WithDispatcher(dispatcher), actor.WithThroughput(1))
+	dispatcher.RunUntilIdle()
+
+	var order []string
+	spawn := func(name string) actor.PID {
+		return engine.SpawnFunc(func(ctx *actor.Context) {
+			if _, ok := ctx.Message().(int); ok {
+				order = append(order, name)
+			}
+		}, "TestThroughputFairness", actor.WithTags(name))
+	}
+
+	a := spawn("a")
+	b := spawn("b")
+	dispatcher.RunUntilIdle()
+
+	for i := 0; i < 3; i++ {
+		engine.Send(context.Background(), a, i)
+	}
+	for i := 0; i < 3; i++ {
+		engine.Send(context.Background(), b, i)
+	}
+	dispatcher.RunUntilIdle()
+
+	is.Equal([]string{"a", "b", "a", "b", "a", "b"}, order) // actors take turns when throughput is reached
+}
+
+func TestPoolDispatcherFullInbox(t *testing.T) {
+	is := is.New(t)
+
+	dispatcher := actor.NewPoolDispatcher(1)
+	defer dispatcher.Stop()
+
+	engine := actor.NewEngine(actor.WithDispatcher(dispatcher))
+
+	received := 0
+	target := engine.SpawnFunc(func(ctx *actor.Context) {
+		if _, ok := ctx.Message().(int); ok {
+			received++
+		}
+	}, "TestPoolDispatcherFullInbox", actor.WithTags("target"), actor.WithInboxSize(1))
+
+	// with a single worker the target can't run while the sender does, so its inbox fills up
+	sent := make(chan struct{})
+	sender := engine.SpawnFunc(func(ctx *actor.Context) {
+		if _, ok := ctx.Message().(string); ok {
+			for i := 0; i < 10; i++ {
+				ctx.Send(ctx.Context(), target, i)
+			}
+			close(sent)
+		}
+	}, "TestPoolDispatcherFullInbox", actor.WithTags("sender"))
+
+	engine.Send(context.Background(), sender, "go")
+	<-sent
+
+	engine.ShutdownAndWait()
+	is.True(received < 10) // messages to the full inbox should have gone to the deadletter instead of blocking the worker
+}
+
+func TestPoolDispatcherScheduleAfterStop(t *testing.T) {
+	is := is.New(t)
+
+	dispatcher := actor.NewPoolDispatcher(1)
+	engine := actor.NewEngine(actor.WithDispatcher(dispatcher))
+
+	received := make(chan int, 1)
+	pid := engine.SpawnFunc(func(ctx *actor.Context) {
+		if n, ok := ctx.Message().(int); ok {
+			received <- n
+		}
+	}, "TestPoolDispatcherScheduleAfterStop")
+
+	engine.Send(context.Background(), pid, 1)
+	is.Equal(1, <-received)
+
+	// timers and remotes can still send once the dispatcher has stopped, which goes to the deadletter
+	dispatcher.Stop()
+	dispatcher.Schedule(func() { t.Error("scheduled after stop") })
+	engine.Send(context.Background(), pid, 2)
+	<-engine.Poison(pid, nil)
+
+	select {
+	case n := <-received:
+		t.Fatalf("received %d after the dispatcher stopped", n)
+	default:
+	}
+}
+
+func TestPoolDispatcherSmallInbox(t *testing.T) {
+	is := is.New(t)
+
+	dispatcher := actor.NewPoolDispatcher(1)
+	defer dispatcher.Stop()
+
+	engine := actor.NewEngine(actor.WithDispatcher(dispatcher))
+
+	// hold on to the only worker, so the actor can't be initialized yet
+	blocked := make(chan struct{})
+	release := make(chan struct{})
+	blocker := engine.SpawnFunc(func(ctx *actor.Context) {
+		if _, ok := ctx.Message().(string); ok {
+			close(blocked)
+			<-release
+		}
+	}, "TestPoolDispatcherSmallInbox", actor.WithTags("blocker"))
+	engine.Send(context.Background(), blocker, "block")
+	<-blocked
+
+	received := make(chan int, 1)
+	pid := engine.SpawnFunc(func(ctx *actor.Context) {
+		if n, ok := ctx.Message().(int); ok {
+			received <- n
+		}
+	}, "TestPoolDispatcherSmallInbox", actor.WithInboxSize(1))
+
+	// initialize is still waiting in the inbox, and doesn't take the place of the message
+	engine.Send(context.Background(), pid, 1)
+	close(release)
+	is.Equal(1, <-received)
+
+	engine.ShutdownAndWait()
+}
@@ -0,0 +1,35 @@
+package durable
+
+import (
+	"bytes"
+	"encoding/gob"
+)
+
+// Codec encodes the messages written to a WAL.
+type Codec interface {
+	Encode(msg any) ([]byte, error)
+	Decode(data []byte) (any, error)
+}
+
+// GobCodec encodes messages with encoding/gob, every message type has to be registered with gob.Register (or remote.Register).
+type GobCodec struct{}
+
+type gobMessage struct {
+	Message any
+}
+
+func (GobCodec) Encode(msg any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(gobMessage{Message: msg}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (GobCodec) Decode(data []byte) (any, error) {
+	var msg gobMessage
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&msg); err != nil {
+		return nil, err
+	}
+	return msg.Message, nil
+}
@@ -0,0 +1,350 @@
+// Package durable provides a write-ahead log that makes the inbox of an actor durable with actor.WithJournal.
+//
+// Every message sent to the actor is appended to the log before it is delivered, and a record is appended once it has been processed.
+// When the log is opened again, the messages that were never processed are replayed to the actor when it starts.
+package durable
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/renevo/actor"
+)
+
+var (
+	ErrClosed = errors.New("wal closed")
+)
+
+const (
+	recordAppend byte = iota + 1
+	recordProcessed
+)
+
+const (
+	// kind, seq and payload length
+	headerSize = 1 + 8 + 4
+	// once everything has been processed, the log is truncated when it is bigger than this
+	truncateSize = 1 << 20
+	// records bigger than this can only come from a corrupt log
+	maxPayloadSize = 64 << 20
+)
+
+type Options struct {
+	// Codec encodes the messages, defaults to GobCodec.
+	Codec Codec
+	// Sync the log to disk after every record, so messages aren't lost when the machine (rather than the process) stops.
+	Sync bool
+}
+
+// WAL is an actor.Journal that appends messages to a file.
+type WAL struct {
+	mu       sync.Mutex
+	path     string
+	options  Options
+	file     *os.File
+	w        *bufio.Writer
+	size     int64
+	seq      uint64
+	open     map[uint64]bool
+	replay   []actor.JournalEntry
+	replayed bool
+}
+
+// Open the write-ahead log at path, creating it when it doesn't exist.
+// The messages in it that weren't processed are rewritten to a new log, which drops a partly written record left at the end by a crash.
+func Open(path string, options Options) (*WAL, error) {
+	if options.Codec == nil {
+		options.Codec = GobCodec{}
+	}
+
+	wal := &WAL{path: path, options: options, open: make(map[uint64]bool)}
+
+	entries, err := wal.read()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := wal.rewrite(entries); err != nil {
+		return nil, err
+	}
+	wal.replay = entries
+
+	return wal, nil
+}
+
+// read the entries that weren't processed.
+func (wal *WAL) read() ([]actor.JournalEntry, error) {
+	f, err := os.Open(wal.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to open %s: %w", wal.path, err)
+	}
+	defer f.Close()
+
+	unprocessed := make(map[uint64]actor.JournalEntry)
+	r := bufio.NewReader(f)
+	for {
+		kind, seq, payload, err := readRecord(r)
+		if err != nil {
+			// anything after a partly written record can't be trusted
+			break
+		}
+
+		wal.seq = max(wal.seq, seq)
+
+		switch kind {
+		case recordAppend:
+			entry, err := wal.decode(seq, payload)
+			if err != nil {
+				return nil, fmt.Errorf("unable to decode message %d in %s: %w", seq, wal.path, err)
+			}
+			unprocessed[seq] = entry
+
+		case recordProcessed:
+			delete(unprocessed, seq)
+		}
+	}
+
+	entries := make([]actor.JournalEntry, 0, len(unprocessed))
+	for _, entry := range unprocessed {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Seq < entries[j].Seq })
+
+	return entries, nil
+}
+
+// rewrite the log with only the given entries, replacing the old one once it has been written.
+func (wal *WAL) rewrite(entries []actor.JournalEntry) error {
+	tmp := wal.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("unable to create %s: %w", tmp, err)
+	}
+
+	wal.file = f
+	wal.w = bufio.NewWriter(f)
+	wal.size = 0
+
+	for _, entry := range entries {
+		payload, err := wal.encode(entry.To, entry.From, entry.Message)
+		if err != nil {
+			_ = f.Close()
+			return err
+		}
+		if err := wal.write(recordAppend, entry.Seq, payload); err != nil {
+			_ = f.Close()
+			return err
+		}
+		wal.open[entry.Seq] = true
+	}
+
+	if err := wal.w.Flush(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	if err := os.Rename(tmp, wal.path); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("unable to replace %s: %w", wal.path, err)
+	}
+
+	return nil
+}
+
+// Append the message to the log, it is on disk (or in the OS when Options.Sync is false) once this returns.
+func (wal *WAL) Append(to actor.PID, from actor.PID, msg any) (uint64, error) {
+	payload, err := wal.encode(to, from, msg)
+	if err != nil {
+		return 0, err
+	}
+
+	wal.mu.Lock()
+	defer wal.mu.Unlock()
+
+	if wal.file == nil {
+		return 0, ErrClosed
+	}
+
+	wal.seq++
+	if err := wal.commit(recordAppend, wal.seq, payload); err != nil {
+		return 0, err
+	}
+	wal.open[wal.seq] = true
+
+	return wal.seq, nil
+}
+
+// Processed marks the message as processed, it won't be replayed.
+func (wal *WAL) Processed(seq uint64) error {
+	wal.mu.Lock()
+	defer wal.mu.Unlock()
+
+	if wal.file == nil {
+		return ErrClosed
+	}
+
+	if !wal.open[seq] {
+		return nil
+	}
+
+	if err := wal.commit(recordProcessed, seq, nil); err != nil {
+		return err
+	}
+	delete(wal.open, seq)
+
+	// nothing in the log is needed anymore
+	if len(wal.open) == 0 && wal.size > truncateSize {
+		if err := wal.file.Truncate(0); err != nil {
+			return err
+		}
+		if _, err := wal.file.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		wal.size = 0
+	}
+
+	return nil
+}
+
+// Replay returns the messages that weren't processed when the log was opened, only the first time it is called.
+func (wal *WAL) Replay() ([]actor.JournalEntry, error) {
+	wal.mu.Lock()
+	defer wal.mu.Unlock()
+
+	if wal.replayed {
+		return nil, nil
+	}
+	wal.replayed = true
+
+	entries := wal.replay
+	wal.replay = nil
+
+	return entries, nil
+}
+
+// Close the log, it can't be appended to anymore.
+func (wal *WAL) Close() error {
+	wal.mu.Lock()
+	defer wal.mu.Unlock()
+
+	if wal.file == nil {
+		return nil
+	}
+
+	err := wal.w.Flush()
+	if closeErr := wal.file.Close(); err == nil {
+		err = closeErr
+	}
+	wal.file = nil
+
+	return err
+}
+
+// commit writes a record, and makes sure it made it to the file.
+func (wal *WAL) commit(kind byte, seq uint64, payload []byte) error {
+	if err := wal.write(kind, seq, payload); err != nil {
+		return err
+	}
+	if err := wal.w.Flush(); err != nil {
+		return err
+	}
+	if wal.options.Sync {
+		return wal.file.Sync()
+	}
+	return nil
+}
+
+// write a record: kind, seq, payload length, payload and a checksum of all of them.
+func (wal *WAL) write(kind byte, seq uint64, payload []byte) error {
+	record := make([]byte, headerSize+len(payload)+4)
+	record[0] = kind
+	binary.BigEndian.PutUint64(record[1:], seq)
+	binary.BigEndian.PutUint32(record[9:], uint32(len(payload)))
+	copy(record[headerSize:], payload)
+	binary.BigEndian.PutUint32(record[headerSize+len(payload):], crc32.ChecksumIEEE(record[:headerSize+len(payload)]))
+
+	n, err := wal.w.Write(record)
+	wal.size += int64(n)
+	return err
+}
+
+func readRecord(r io.Reader) (byte, uint64, []byte, error) {
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, 0, nil, err
+	}
+
+	size := binary.BigEndian.Uint32(header[9:])
+	if size > maxPayloadSize {
+		return 0, 0, nil, errors.New("record too big")
+	}
+
+	rest := make([]byte, int(size)+4)
+	if _, err := io.ReadFull(r, rest); err != nil {
+		return 0, 0, nil, err
+	}
+
+	payload := rest[:size]
+	checksum := crc32.NewIEEE()
+	_, _ = checksum.Write(header)
+	_, _ = checksum.Write(payload)
+	if checksum.Sum32() != binary.BigEndian.Uint32(rest[size:]) {
+		return 0, 0, nil, errors.New("checksum mismatch")
+	}
+
+	return header[0], binary.BigEndian.Uint64(header[1:]), payload, nil
+}
+
+// encode the message with the PIDs it was sent to and from.
+func (wal *WAL) encode(to actor.PID, from actor.PID, msg any) ([]byte, error) {
+	data, err := wal.options.Codec.Encode(msg)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode message: %w", err)
+	}
+
+	var payload []byte
+	for _, s := range []string{to.Address, to.ID, from.Address, from.ID} {
+		payload = binary.AppendUvarint(payload, uint64(len(s)))
+		payload = append(payload, s...)
+	}
+
+	return append(payload, data...), nil
+}
+
+func (wal *WAL) decode(seq uint64, payload []byte) (actor.JournalEntry, error) {
+	var fields [4]string
+	for i := range fields {
+		n, read := binary.Uvarint(payload)
+		if read <= 0 || uint64(len(payload)-read) < n {
+			return actor.JournalEntry{}, errors.New("invalid pids")
+		}
+		fields[i] = string(payload[read : read+int(n)])
+		payload = payload[read+int(n):]
+	}
+
+	msg, err := wal.options.Codec.Decode(payload)
+	if err != nil {
+		return actor.JournalEntry{}, err
+	}
+
+	return actor.JournalEntry{
+		Seq:     seq,
+		To:      actor.PID{Address: fields[0], ID: fields[1]},
+		From:    actor.PID{Address: fields[2], ID: fields[3]},
+		Message: msg,
+	}, nil
+}
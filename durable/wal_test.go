@@ -0,0 +1,164 @@
+package durable_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/renevo/actor"
+	"github.com/renevo/actor/durable"
+)
+
+func TestReplayUnprocessed(t *testing.T) {
+	is := is.New(t)
+
+	path := filepath.Join(t.TempDir(), "inbox.wal")
+	wal, err := durable.Open(path, durable.Options{})
+	is.NoErr(err)
+
+	engine := actor.NewEngine()
+	t.Cleanup(engine.ShutdownAndWait)
+
+	started := make(chan struct{})
+	gate := make(chan struct{})
+	pid := engine.SpawnFunc(func(ctx *actor.Context) {
+		if ctx.Message() == "a" {
+			close(started)
+			<-gate
+		}
+	}, "inbox", actor.WithJournal(wal))
+
+	engine.Send(context.Background(), pid, "a")
+	<-started
+	engine.Send(context.Background(), pid, "b")
+	engine.Send(context.Background(), pid, "c")
+
+	// stop skips the messages behind a, they stay in the journal
+	stopped := engine.Stop(pid)
+	close(gate)
+	<-stopped
+	is.NoErr(wal.Close())
+
+	wal, err = durable.Open(path, durable.Options{})
+	is.NoErr(err)
+	t.Cleanup(func() { _ = wal.Close() })
+
+	var mu sync.Mutex
+	var received []any
+	done := make(chan struct{})
+	engine.SpawnFunc(func(ctx *actor.Context) {
+		switch ctx.Message().(type) {
+		case actor.Initialized, actor.Started, actor.Stopped:
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, ctx.Message())
+		if len(received) == 2 {
+			close(done)
+		}
+	}, "inbox", actor.WithJournal(wal))
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("messages were not replayed")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	is.Equal([]any{"b", "c"}, received) // only the unprocessed messages are replayed, in order
+}
+
+func TestTornTail(t *testing.T) {
+	is := is.New(t)
+
+	path := filepath.Join(t.TempDir(), "inbox.wal")
+	wal, err := durable.Open(path, durable.Options{Sync: true})
+	is.NoErr(err)
+
+	to := actor.NewPID("local", "inbox")
+	for _, msg := range []string{"a", "b", "c"} {
+		_, err := wal.Append(to, actor.PID{}, msg)
+		is.NoErr(err)
+	}
+	is.NoErr(wal.Processed(1))
+	is.NoErr(wal.Close())
+
+	// a crash while writing the next record
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	is.NoErr(err)
+	_, err = f.Write([]byte{1, 0, 0, 0, 0, 0, 0, 0, 4, 0, 0})
+	is.NoErr(err)
+	is.NoErr(f.Close())
+
+	wal, err = durable.Open(path, durable.Options{})
+	is.NoErr(err)
+	t.Cleanup(func() { _ = wal.Close() })
+
+	entries, err := wal.Replay()
+	is.NoErr(err)
+	is.Equal(len(entries), 2)
+	is.Equal(entries[0].Seq, uint64(2))
+	is.Equal(entries[0].To, to)
+	is.Equal(entries[0].Message, "b")
+	is.Equal(entries[1].Message, "c")
+
+	entries, err = wal.Replay()
+	is.NoErr(err)
+	is.Equal(len(entries), 0) // only replayed once
+
+	seq, err := wal.Append(to, actor.PID{}, "d")
+	is.NoErr(err)
+	is.Equal(seq, uint64(4)) // sequence numbers aren't reused
+}
+
+type order struct {
+	ID string
+}
+
+// orderCodec encodes orders as their ID.
+type orderCodec struct{}
+
+func (orderCodec) Encode(msg any) ([]byte, error) {
+	o, ok := msg.(order)
+	if !ok {
+		return nil, errors.New("not an order")
+	}
+	return []byte(o.ID), nil
+}
+
+func (orderCodec) Decode(data []byte) (any, error) {
+	return order{ID: string(data)}, nil
+}
+
+func TestCodec(t *testing.T) {
+	is := is.New(t)
+
+	path := filepath.Join(t.TempDir(), "orders.wal")
+	wal, err := durable.Open(path, durable.Options{Codec: orderCodec{}})
+	is.NoErr(err)
+
+	to := actor.NewPID("local", "orders")
+	_, err = wal.Append(to, actor.PID{}, order{ID: "42"})
+	is.NoErr(err)
+
+	_, err = wal.Append(to, actor.PID{}, "not an order")
+	is.True(err != nil) // the codec can't encode it
+	is.NoErr(wal.Close())
+
+	wal, err = durable.Open(path, durable.Options{Codec: orderCodec{}})
+	is.NoErr(err)
+	t.Cleanup(func() { _ = wal.Close() })
+
+	entries, err := wal.Replay()
+	is.NoErr(err)
+	is.Equal(len(entries), 1)
+	is.Equal(entries[0].Message, order{ID: "42"})
+}
@@ -2,10 +2,11 @@ package actor
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"reflect"
-	"strings"
 	"sync"
+	"sync/atomic"
 )
 
 const (
@@ -14,98 +15,230 @@ const (
 )
 
 type Engine struct {
+	address    string
 	pid        PID
 	deadletter PID
 	registry   *registry
+	pubsub     *pubsub
 	options    *Options
+	passivated *safemap[string, *passivation]
+	kinds      *safemap[string, *kind]
+	activateMu sync.Mutex
+	stopping   atomic.Bool
 }
 
 func NewEngine(defaultOpts ...Option) *Engine {
 	options := &Options{
-		InboxSize:    defaultInboxSize,
-		MaxRestarts:  defaultMaxRestarts,
-		RestartDelay: defaultRestartDelay,
-		Context:      context.Background(),
-		Logger:       slog.Default(),
+		InboxSize:       defaultInboxSize,
+		MaxRestarts:     defaultMaxRestarts,
+		RestartDelay:    defaultRestartDelay,
+		Context:         context.Background(),
+		Logger:          slog.Default(),
+		Metrics:         nopMetrics{},
+		Dispatcher:      GoroutineDispatcher{},
+		Throughput:      defaultThroughput,
+		Clock:           realClock{},
+		ShutdownTimeout: defaultShutdownTimeout,
 	}
 	for _, opt := range defaultOpts {
 		opt(options)
 	}
 
 	e := &Engine{
+		address: LocalAddress,
 		registry: &registry{
 			lookup: make(map[string]Processor),
 		},
-		options: options,
+		pubsub:     newPubSub(),
+		options:    options,
+		passivated: newMap[string, *passivation](),
+		kinds:      newMap[string, *kind](),
+	}
+
+	if options.Remote != nil {
+		e.address = options.Remote.Address()
 	}
 
 	// put the engine into the registry
 	e.registry.engine = e
 	e.pid = e.SpawnFunc(func(ctx *Context) {
 		switch msg := ctx.Message().(type) {
-		case Initialized, Started, Stopped:
+		case Initialized, Started, Stopped, Restarting, Restarted:
 			ctx.Log().Debug("engine state change", "state", reflect.TypeOf(msg))
 
+		case SpawnRequest:
+			ctx.Respond(ctx.Engine().spawnRequested(msg))
 		}
 	}, "engine")
 
 	e.deadletter = e.SpawnFunc(func(ctx *Context) {
 		switch msg := ctx.Message().(type) {
-		case Initialized, Started, Stopped:
+		case Initialized, Started, Stopped, Restarting, Restarted:
 			// if we have anything, add it here
 			ctx.Log().Debug("deadletter state change", "state", reflect.TypeOf(msg))
 
 		default:
 			ctx.Log().Warn("Deadletter", "to", ctx.target, "from", ctx.sender, "type", reflect.TypeOf(ctx.Message()))
+			ctx.engine.options.Metrics.Deadletter(ctx.target, pidTag(ctx.target))
 			// TODO: publish deadletter to Events once we have them
 		}
 	}, "engine", WithTags("deadletter"), WithInboxSize(defaultInboxSize*4))
 
+	if options.Remote != nil {
+		options.Remote.Start(e)
+	}
+
 	return e
 }
 
 func (e *Engine) Spawn(receiver Receiver, name string, opts ...Option) PID {
+	pid, err := e.TrySpawn(receiver, name, opts...)
+	if err != nil {
+		e.options.Logger.Warn("Attempt to spawn duplicate actor.", "pid", pid, "err", err)
+	}
+
+	return pid
+}
+
+// TrySpawn spawns an actor, returning ErrDuplicateActor when an actor with the same ID already exists and the spawn policy is SpawnPolicyFail.
+func (e *Engine) TrySpawn(receiver Receiver, name string, opts ...Option) (PID, error) {
 	options := copyOptions(e.options, receiver)
 	options.Name = name
 	for _, opt := range opts {
 		opt(options)
 	}
 
-	proc := newProcessor(e, options)
-	return e.SpawnProcessor(proc)
+	pid, _, err := e.spawn(newProcessor(e, options), options.SpawnPolicy)
+	return pid, err
 }
 
 func (e *Engine) SpawnFunc(receiver ReceiverFunc, name string, opts ...Option) PID {
 	return e.Spawn(receiver, name, opts...)
 }
 
+// SpawnProcessor registers and starts the processor, a processor with the same ID as an existing actor is not started.
 func (e *Engine) SpawnProcessor(proc Processor) PID {
-	e.registry.add(proc)
-	proc.Start()
-	return proc.PID()
+	pid, _, err := e.spawn(proc, SpawnPolicyFail)
+	if err != nil {
+		e.options.Logger.Warn("Attempt to spawn duplicate actor.", "pid", pid, "err", err)
+	}
+
+	return pid
+}
+
+// spawn registers and starts the processor, returning false when the existing actor was kept because of SpawnPolicyExisting.
+func (e *Engine) spawn(proc Processor, policy SpawnPolicy) (PID, bool, error) {
+	pid := proc.PID()
+
+	// anything left in the journal goes ahead of whatever is sent once the actor is registered
+	if r, ok := proc.(interface{ replay() }); ok {
+		r.replay()
+	}
+
+	for {
+		// a passivated actor still exists, it just isn't running
+		_, passivated := e.passivated.Get(pid.ID)
+		if !passivated && e.registry.add(proc) == nil {
+			proc.Start()
+			return pid, true, nil
+		}
+
+		switch policy {
+		case SpawnPolicyExisting:
+			return pid, false, nil
+
+		case SpawnPolicyReplace:
+			if passivated {
+				e.forget(pid)
+				continue
+			}
+
+			<-e.Poison(pid, nil)
+
+		default:
+			return pid, false, fmt.Errorf("%w: %s", ErrDuplicateActor, pid)
+		}
+	}
 }
 
 func (e *Engine) Address() string {
-	return e.pid.Address
+	return e.address
+}
+
+// Clock the engine uses for everything time based.
+func (e *Engine) Clock() Clock {
+	return e.options.Clock
 }
 
+// Send a message to the given PID, once the engine is shutting down with ShutdownContext the message is deadlettered instead.
 func (e *Engine) Send(ctx context.Context, to PID, msg any) {
-	e.send(ctx, to, msg, e.pid)
+	e.SendWithSender(ctx, to, msg, e.pid)
+}
+
+// SendWithSender sends a message to the given PID as if it was sent by from.
+func (e *Engine) SendWithSender(ctx context.Context, to PID, msg any, from PID) {
+	if e.stopping.Load() {
+		e.deadLetter(ctx, to, msg, from)
+		return
+	}
+
+	e.send(ctx, to, msg, from)
+}
+
+// Relay passes on a message that was already accepted somewhere else as if it was sent by from, e.g. one received from a remote engine or forwarded by an actor.
+// Unlike SendWithSender, it is still delivered while the engine is shutting down with ShutdownContext.
+func (e *Engine) Relay(ctx context.Context, to PID, msg any, from PID) {
+	e.send(ctx, to, msg, from)
 }
 
 func (e *Engine) send(ctx context.Context, to PID, msg any, from PID) {
+	if e.isRemote(to) {
+		if ctx == nil {
+			ctx = e.options.Context
+		}
+		e.options.Remote.Send(ctx, to, msg, from)
+		return
+	}
+
 	proc := e.registry.get(to)
+	if proc == nil {
+		proc = e.activate(to)
+	}
+
 	if proc == nil {
 		proc = e.registry.get(e.deadletter)
 	}
 
+	// nothing to do with it once the engine has shut down
+	if proc == nil {
+		return
+	}
+
 	proc.Send(ctx, to, msg, from)
 }
 
-func (e *Engine) Poison(to PID, wg *sync.WaitGroup) {
+// DeadLetter hands a message that can't be delivered to the deadletter actor, as if it was sent to the given PID.
+func (e *Engine) DeadLetter(ctx context.Context, to PID, msg any, from PID) {
+	e.deadLetter(ctx, to, msg, from)
+}
+
+// deadLetter hands a message that couldn't be delivered to the deadletter actor.
+func (e *Engine) deadLetter(ctx context.Context, to PID, msg any, from PID) {
+	if proc := e.registry.get(e.deadletter); proc != nil {
+		proc.Send(ctx, to, msg, from)
+	}
+}
+
+// Poison stops the actor once it has processed the messages already in its inbox, wg is done once it has stopped.
+// The returned channel is closed once the actor has stopped.
+func (e *Engine) Poison(to PID, wg *sync.WaitGroup) <-chan struct{} {
+	done := make(chan struct{})
+
 	proc := e.registry.get(to)
 	if proc == nil {
-		return
+		e.forget(to)
+		close(done)
+		return done
 	}
 
 	if wg != nil {
@@ -113,19 +246,64 @@ func (e *Engine) Poison(to PID, wg *sync.WaitGroup) {
 	}
 
 	// we intentionally use background here as we won't use it, so we dn't need it (yet)
-	e.send(context.Background(), to, poisonPill{wg: wg}, e.pid)
+	e.send(context.Background(), to, poisonPill{wg: wg, done: done}, e.pid)
+
+	return done
+}
+
+// Stop the actor right away, ahead of any messages in its inbox, which are deadlettered.
+// The returned channel is closed once the actor has stopped.
+func (e *Engine) Stop(to PID) <-chan struct{} {
+	done := make(chan struct{})
+
+	proc := e.registry.get(to)
+	if proc == nil {
+		e.forget(to)
+		close(done)
+		return done
+	}
+
+	e.send(context.Background(), to, stop{done: done}, e.pid)
+
+	return done
+}
+
+// Watch the target actor, the watcher will receive a Terminated message once the target has stopped.
+// If the target doesn't exist, Terminated is sent right away.
+func (e *Engine) Watch(target PID, watcher PID) {
+	if proc, ok := e.registry.get(target).(*processor); ok && proc.addWatcher(watcher) {
+		return
+	}
+
+	if e.watchPassivated(target, watcher) {
+		return
+	}
+
+	e.send(context.Background(), watcher, Terminated{PID: target}, target)
+}
+
+// Unwatch stops watching the target actor.
+func (e *Engine) Unwatch(target PID, watcher PID) {
+	if proc, ok := e.registry.get(target).(*processor); ok {
+		proc.removeWatcher(watcher)
+	}
 }
 
 func (e *Engine) GetPID(name string, tags ...string) PID {
-	pid := PID{Address: LocalAddress, ID: strings.Join(append([]string{name}, tags...), pidSeparator)}
+	pid := NewPID(e.address, name, tags...)
 	proc := e.registry.get(pid)
 	if proc == nil {
+		if _, ok := e.passivated.Get(pid.ID); ok || e.isGrain(pid) {
+			return pid
+		}
 		return e.deadletter
 	}
 
 	return pid
 }
 
+// Shutdown poisons all actors and then the engine itself, wg is done once the engine has stopped.
+// Shutdown waits for the actors to stop without a timeout, use ShutdownContext to limit how long it can take.
 func (e *Engine) Shutdown(wg *sync.WaitGroup) {
 	var toShutdown []PID
 
@@ -161,9 +339,15 @@ func (e *Engine) Shutdown(wg *sync.WaitGroup) {
 
 	shutdownWG.Wait()
 
+	e.forgetPassivated()
+
 	// tell our engine/deadletter to die
 	e.Poison(e.pid, wg)
 	e.Poison(e.deadletter, wg)
+
+	if e.options.Remote != nil {
+		e.options.Remote.Stop()
+	}
 }
 
 func (e *Engine) ShutdownAndWait() {
@@ -2,7 +2,9 @@ package actor_test
 
 import (
 	"context"
+	"errors"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -56,6 +58,65 @@ func TestRestarts(t *testing.T) {
 	engine.ShutdownAndWait()
 }
 
+type restartReceiver struct {
+	instance int
+	events   chan<- any
+}
+
+func (r *restartReceiver) Receive(ctx *actor.Context) {
+	switch msg := ctx.Message().(type) {
+	case actor.Restarting, actor.Stopped:
+		r.events <- msg
+	case actor.Restarted:
+		r.events <- msg
+		r.events <- r.instance
+	case string:
+		panic(msg)
+	}
+}
+
+func TestRestartHooks(t *testing.T) {
+	is := is.New(t)
+
+	engine := actor.NewEngine()
+	defer engine.ShutdownAndWait()
+
+	events := make(chan any, 10)
+	instances := 0
+	producer := func() actor.Receiver {
+		instances++
+		return &restartReceiver{instance: instances, events: events}
+	}
+
+	// both lifecycle messages go through middleware
+	middleware := make(chan any, 10)
+	record := func(next actor.ReceiverFunc) actor.ReceiverFunc {
+		return func(ctx *actor.Context) {
+			switch msg := ctx.Message().(type) {
+			case actor.Restarting, actor.Restarted:
+				middleware <- msg
+			}
+			next(ctx)
+		}
+	}
+
+	pid := engine.Spawn(producer(), "TestRestartHooks", actor.WithProducer(producer), actor.WithRecreateOnRestart(), actor.WithRestartDelay(time.Millisecond), actor.WithMiddleware(record))
+
+	engine.Send(context.Background(), pid, "boom")
+	is.Equal(actor.Restarting{Reason: "boom", Message: "boom"}, <-events) // the panicking receiver is told why it is restarting
+	is.Equal(actor.Stopped{}, <-events)                                   // and still stopped, so its cleanup runs
+	is.Equal(actor.Restarted{Count: 1}, <-events)
+	is.Equal(2, <-events) // a new receiver was created for the restart
+	is.Equal(actor.Restarting{Reason: "boom", Message: "boom"}, <-middleware)
+	is.Equal(actor.Restarted{Count: 1}, <-middleware)
+
+	engine.Send(context.Background(), pid, "again")
+	is.Equal(actor.Restarting{Reason: "again", Message: "again"}, <-events)
+	is.Equal(actor.Stopped{}, <-events)
+	is.Equal(actor.Restarted{Count: 2}, <-events)
+	is.Equal(3, <-events)
+}
+
 func TestProcessInitStartOrder(t *testing.T) {
 	is := is.New(t)
 
@@ -148,6 +209,107 @@ func TestPoisonWaitGroup(t *testing.T) {
 	is.Equal(int32(1), atomic.LoadInt32(&x))
 }
 
+func TestPoisonRemaining(t *testing.T) {
+	is := is.New(t)
+
+	metrics := actor.NewPrometheusMetrics()
+	engine := actor.NewEngine(actor.WithMetrics(metrics))
+
+	release := make(chan struct{})
+	pid := engine.SpawnFunc(func(ctx *actor.Context) {
+		if msg, ok := ctx.Message().(string); ok && msg == "block" {
+			<-release
+		}
+	}, "TestPoisonRemaining", actor.WithTags("remaining"))
+
+	engine.Send(context.Background(), pid, "block")
+	engine.Poison(pid, nil)
+	engine.Send(context.Background(), pid, "late")
+
+	// a second poison queued behind the first must still complete
+	pwg := &sync.WaitGroup{}
+	engine.Poison(pid, pwg)
+	close(release)
+	pwg.Wait()
+
+	engine.ShutdownAndWait()
+
+	sb := &strings.Builder{}
+	_, err := metrics.WriteTo(sb)
+	is.NoErr(err)
+	is.True(strings.Contains(sb.String(), `actor_deadletters_total{tag="remaining"} 1`)) // messages behind the poison go to the deadletter
+}
+
+func TestStop(t *testing.T) {
+	is := is.New(t)
+
+	metrics := actor.NewPrometheusMetrics()
+	engine := actor.NewEngine(actor.WithMetrics(metrics))
+
+	blocked := make(chan struct{})
+	release := make(chan struct{})
+	var processed, stopped atomic.Int32
+	pid := engine.SpawnFunc(func(ctx *actor.Context) {
+		switch ctx.Message().(type) {
+		case actor.Stopped:
+			stopped.Add(1)
+
+		case int:
+			processed.Add(1)
+			if processed.Load() == 1 {
+				close(blocked)
+				<-release
+			}
+		}
+	}, "TestStop", actor.WithTags("stop"))
+
+	for i := 0; i < 5; i++ {
+		engine.Send(context.Background(), pid, i)
+	}
+	<-blocked
+
+	// stop skips ahead of everything in the inbox
+	done := engine.Stop(pid)
+	close(release)
+	<-done
+
+	is.Equal(int32(1), processed.Load())
+	is.Equal(int32(1), stopped.Load())
+
+	// stopping an actor that is already gone is done right away
+	<-engine.Stop(pid)
+
+	engine.ShutdownAndWait()
+
+	sb := &strings.Builder{}
+	_, err := metrics.WriteTo(sb)
+	is.NoErr(err)
+	is.True(strings.Contains(sb.String(), `actor_deadletters_total{tag="stop"} 4`)) // the rest of the inbox goes to the deadletter
+}
+
+func TestPoisonDone(t *testing.T) {
+	is := is.New(t)
+
+	engine := actor.NewEngine()
+	defer engine.ShutdownAndWait()
+
+	var processed atomic.Int32
+	pid := engine.SpawnFunc(func(ctx *actor.Context) {
+		if _, ok := ctx.Message().(int); ok {
+			time.Sleep(time.Millisecond)
+			processed.Add(1)
+		}
+	}, "TestPoisonDone")
+
+	for i := 0; i < 5; i++ {
+		engine.Send(context.Background(), pid, i)
+	}
+
+	// poison lets the actor finish its inbox first
+	<-engine.Poison(pid, nil)
+	is.Equal(int32(5), processed.Load())
+}
+
 type tick struct{}
 type tickReceiver struct {
 	ticks int
@@ -237,3 +399,251 @@ func BenchmarkEngineRequest(b *testing.B) {
 	engine.Poison(pid, wg)
 	wg.Wait()
 }
+
+func TestWatch(t *testing.T) {
+	is := is.New(t)
+
+	engine := actor.NewEngine()
+	terminated := make(chan actor.PID, 2)
+
+	target := engine.SpawnFunc(func(ctx *actor.Context) {}, "TestWatch", actor.WithTags("target"))
+	engine.SpawnFunc(func(ctx *actor.Context) {
+		switch msg := ctx.Message().(type) {
+		case actor.Started:
+			ctx.Watch(target)
+			ctx.Watch(actor.NewPID(actor.LocalAddress, "TestWatch", "missing"))
+		case actor.Terminated:
+			terminated <- msg.PID
+		}
+	}, "TestWatch", actor.WithTags("watcher"))
+
+	is.Equal(actor.NewPID(actor.LocalAddress, "TestWatch", "missing"), <-terminated)
+
+	engine.Poison(target, nil)
+	is.Equal(target, <-terminated)
+
+	engine.ShutdownAndWait()
+}
+
+func TestFind(t *testing.T) {
+	is := is.New(t)
+
+	engine := actor.NewEngine()
+	defer engine.ShutdownAndWait()
+
+	noop := func(ctx *actor.Context) {}
+	w1 := engine.SpawnFunc(noop, "worker", actor.WithTags("1"))
+	w2 := engine.SpawnFunc(noop, "worker", actor.WithTags("2"))
+	w3 := engine.SpawnFunc(noop, "worker", actor.WithTags("3", "eu"))
+	other := engine.SpawnFunc(noop, "other", actor.WithTags("1"))
+
+	is.Equal([]actor.PID{w1, w2}, engine.Find("worker", "*"))
+	is.Equal([]actor.PID{w1, w2, w3}, engine.Find("worker", ">"))
+	is.Equal([]actor.PID{other, w1}, engine.Find("*", "1"))
+	is.Equal([]actor.PID{w3}, engine.Find("worker", "3", "eu"))
+	is.Equal(0, len(engine.Find("missing")))
+
+	parent := engine.SpawnFunc(func(ctx *actor.Context) {
+		if _, ok := ctx.Message().(string); ok {
+			a := ctx.SpawnFunc(noop, "a")
+			b := ctx.SpawnFunc(noop, "b")
+			ctx.Respond([]actor.PID{a, b})
+		}
+	}, "parent")
+	children, err := engine.Request(parent, "spawn", time.Second)
+	is.NoErr(err)
+	is.Equal(children, engine.FindPrefix(parent))
+
+	pids := engine.PIDs()
+	is.True(len(pids) >= 5)
+	for i := 1; i < len(pids); i++ {
+		is.True(pids[i-1].ID < pids[i].ID) // sorted by id
+	}
+}
+
+func TestPIDsOnlyActors(t *testing.T) {
+	is := is.New(t)
+
+	engine := actor.NewEngine()
+	defer engine.ShutdownAndWait()
+
+	engine.RegisterKind("counter", func() actor.Receiver { return actor.ReceiverFunc(func(*actor.Context) {}) })
+
+	pid := engine.SpawnFunc(func(ctx *actor.Context) {
+		switch ctx.Message().(type) {
+		case string:
+			// the response actor of the request is registered while we are in here
+			ctx.Respond(ctx.Engine().PIDs())
+		case actor.Terminated:
+		}
+	}, "actor")
+
+	// watching a grain that isn't activated doesn't make it an actor
+	engine.Watch(engine.GetGrain("counter", "1"), pid)
+
+	pids, err := engine.Request(pid, "pids", time.Second)
+	is.NoErr(err)
+	is.Equal([]actor.PID{pid}, pids)
+	is.Equal([]actor.PID{pid}, engine.Find("*"))
+
+	// grains are actors once they are activated
+	grain := engine.GetGrain("counter", "2")
+	engine.Send(context.Background(), grain, "activate")
+	is.Equal([]actor.PID{pid, grain}, engine.PIDs())
+}
+
+func TestTrySpawn(t *testing.T) {
+	is := is.New(t)
+
+	engine := actor.NewEngine()
+	defer engine.ShutdownAndWait()
+
+	var stopped atomic.Int32
+	first := func(ctx *actor.Context) {
+		switch ctx.Message().(type) {
+		case actor.Stopped:
+			stopped.Add(1)
+		case string:
+			ctx.Respond("first")
+		}
+	}
+	second := func(ctx *actor.Context) {
+		if _, ok := ctx.Message().(string); ok {
+			ctx.Respond("second")
+		}
+	}
+
+	pid, err := engine.TrySpawn(actor.ReceiverFunc(first), "TestTrySpawn")
+	is.NoErr(err)
+
+	_, err = engine.TrySpawn(actor.ReceiverFunc(second), "TestTrySpawn")
+	is.True(errors.Is(err, actor.ErrDuplicateActor)) // the default policy fails
+
+	existing, err := engine.TrySpawn(actor.ReceiverFunc(second), "TestTrySpawn", actor.WithSpawnPolicy(actor.SpawnPolicyExisting))
+	is.NoErr(err)
+	is.Equal(pid, existing)
+
+	resp, err := engine.Request(pid, "who", time.Second)
+	is.NoErr(err)
+	is.Equal("first", resp) // the existing actor is untouched
+
+	replaced, err := engine.TrySpawn(actor.ReceiverFunc(second), "TestTrySpawn", actor.WithSpawnPolicy(actor.SpawnPolicyReplace))
+	is.NoErr(err)
+	is.Equal(pid, replaced)
+	is.Equal(int32(1), stopped.Load()) // the existing actor was stopped first
+
+	resp, err = engine.Request(pid, "who", time.Second)
+	is.NoErr(err)
+	is.Equal("second", resp)
+}
+
+// deadlettered tells which PIDs had messages deadlettered.
+type deadlettered struct {
+	actor.Metrics
+	pids chan actor.PID
+}
+
+func (d *deadlettered) Deadletter(pid actor.PID, _ string) {
+	select {
+	case d.pids <- pid:
+	default:
+	}
+}
+
+func TestShutdownContext(t *testing.T) {
+	is := is.New(t)
+
+	metrics := &deadlettered{Metrics: actor.NewPrometheusMetrics(), pids: make(chan actor.PID, 16)}
+	engine := actor.NewEngine(actor.WithMetrics(metrics))
+
+	var mu sync.Mutex
+	var events []string
+	record := func(event string) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	}
+
+	release := make(chan struct{})
+	var processed atomic.Int32
+
+	parent := engine.SpawnFunc(func(ctx *actor.Context) {
+		switch ctx.Message().(type) {
+		case actor.Started:
+			ctx.SpawnFunc(func(ctx *actor.Context) {
+				if _, ok := ctx.Message().(actor.Stopped); ok {
+					record("child stopped")
+				}
+			}, "child")
+
+		case actor.Stopped:
+			record("parent stopped")
+
+		case int:
+			<-release
+			processed.Add(1)
+		}
+	}, "parent")
+
+	pong := make(chan struct{})
+	witness := engine.SpawnFunc(func(ctx *actor.Context) {
+		if _, ok := ctx.Message().(string); ok {
+			pong <- struct{}{}
+		}
+	}, "witness")
+
+	for i := 0; i < 10; i++ {
+		engine.Send(context.Background(), parent, i)
+	}
+
+	errCh := make(chan error)
+	go func() {
+		errCh <- engine.ShutdownContext(context.Background())
+	}()
+
+	// the witness answers until the engine is shutting down, then its messages are deadlettered
+	for stopping := false; !stopping; {
+		engine.Send(context.Background(), witness, "ping")
+		select {
+		case <-pong:
+		case pid := <-metrics.pids:
+			stopping = pid.Equals(witness)
+		}
+	}
+
+	// messages from outside aren't accepted once shutting down, but what was already sent is processed
+	_, err := engine.Request(parent, 1, time.Second)
+	is.True(errors.Is(err, actor.ErrEngineStopping))
+	engine.Send(context.Background(), parent, 11)
+
+	// relayed messages were already accepted elsewhere, e.g. by a remote, so they still are
+	engine.Relay(context.Background(), parent, 12, witness)
+	close(release)
+
+	is.NoErr(<-errCh)
+	is.Equal(int32(11), processed.Load())
+	is.Equal([]string{"child stopped", "parent stopped"}, events) // children stop before their parents
+}
+
+func TestShutdownContextTimeout(t *testing.T) {
+	is := is.New(t)
+
+	engine := actor.NewEngine(actor.WithShutdownTimeout(50 * time.Millisecond))
+
+	release := make(chan struct{})
+	defer close(release)
+
+	stuck := engine.SpawnFunc(func(ctx *actor.Context) {
+		if _, ok := ctx.Message().(actor.Stopped); ok {
+			<-release
+		}
+	}, "stuck")
+	engine.SpawnFunc(func(ctx *actor.Context) {}, "fine")
+
+	err := engine.ShutdownContext(context.Background())
+
+	var shutdownErr *actor.ShutdownError
+	is.True(errors.As(err, &shutdownErr))
+	is.Equal(map[actor.ShutdownPhase][]actor.PID{actor.ShutdownPhaseActors: {stuck}}, shutdownErr.Laggards) // only the stuck actor didn't finish
+	is.Equal(0, len(engine.PIDs()))
+}
@@ -21,6 +21,10 @@ func (f *foo) Receive(ctx *actor.Context) {
 	switch msg := ctx.Message().(type) {
 	case actor.Started:
 		ctx.Log().Info("foo started")
+	case actor.Restarting:
+		ctx.Log().Info("foo restarting", "reason", msg.Reason)
+	case actor.Restarted:
+		ctx.Log().Info("foo restarted", "count", msg.Count)
 	case *message:
 		if msg.data == "failed" {
 			panic("I failed processing this message")
@@ -31,7 +35,7 @@ func (f *foo) Receive(ctx *actor.Context) {
 
 func main() {
 	engine := actor.NewEngine(actor.WithRestartDelay(time.Second * 1))
-	pid := engine.Spawn(newFoo(), "foo", actor.WithMaxRestarts(3))
+	pid := engine.Spawn(newFoo(), "foo", actor.WithMaxRestarts(3), actor.WithProducer(newFoo), actor.WithRecreateOnRestart())
 	engine.Send(context.Background(), pid, &message{data: "failed"})
 	engine.Send(context.Background(), pid, &message{data: "failed"})
 	engine.Send(context.Background(), pid, &message{data: "failed"})
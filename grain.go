@@ -0,0 +1,73 @@
+package actor
+
+import (
+	"strings"
+	"time"
+)
+
+var (
+	defaultGrainIdleTimeout = 5 * time.Minute
+)
+
+type kind struct {
+	props Props
+}
+
+// RegisterKind registers a Producer for a kind of virtual actor (grain).
+// Grains of the kind are activated on their first message, and passivated once idle, defaulting to 5 minutes unless WithIdleTimeout is provided.
+func (e *Engine) RegisterKind(name string, producer Producer, opts ...Option) {
+	e.RegisterKindProps(name, NewProps(producer, opts...))
+}
+
+// RegisterKindProps registers the Props for a kind of virtual actor (grain), see RegisterKind.
+func (e *Engine) RegisterKindProps(name string, props Props) {
+	e.kinds.Set(name, &kind{props: props})
+}
+
+// GetGrain returns the PID of the grain with the given kind and id, the grain doesn't need to be spawned to be sent messages.
+func (e *Engine) GetGrain(kind string, id string) PID {
+	return NewPID(e.address, kind, id)
+}
+
+// GetGrain returns the PID of the grain with the given kind and id.
+func (c *Context) GetGrain(kind string, id string) PID {
+	return c.engine.GetGrain(kind, id)
+}
+
+func (e *Engine) grainKind(pid PID) (string, string, *kind) {
+	name, id, ok := strings.Cut(pid.ID, pidSeparator)
+	if !ok || id == "" {
+		return "", "", nil
+	}
+
+	k, _ := e.kinds.Get(name)
+	return name, id, k
+}
+
+func (e *Engine) isGrain(pid PID) bool {
+	_, _, k := e.grainKind(pid)
+	return k != nil
+}
+
+// activateGrain must be called while holding the activateMu lock.
+func (e *Engine) activateGrain(pid PID, watchers map[string]PID) Processor {
+	name, id, k := e.grainKind(pid)
+	if k == nil {
+		return nil
+	}
+
+	options := copyOptions(e.options, nil)
+	options.Name = name
+	options.Tags = []string{id}
+	options.IdleTimeout = defaultGrainIdleTimeout
+	for _, opt := range k.props.options(nil) {
+		opt(options)
+	}
+
+	proc := newProcessor(e, options)
+	proc.grain = true
+	proc.watchers = watchers
+	e.SpawnProcessor(proc)
+
+	return proc
+}
@@ -0,0 +1,96 @@
+package actor_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/renevo/actor"
+	"github.com/renevo/actor/actortest"
+)
+
+type increment struct {
+	reply actor.PID
+}
+
+type counter struct {
+	count int
+}
+
+func (c *counter) Receive(ctx *actor.Context) {
+	if msg, ok := ctx.Message().(increment); ok {
+		c.count++
+		ctx.Send(ctx.Context(), msg.reply, c.count)
+	}
+}
+
+func TestGrains(t *testing.T) {
+	is := is.New(t)
+
+	d := actortest.NewDeterministic()
+	probe := actortest.NewProbe(t, d.Engine)
+	activations := 0
+
+	d.Engine.RegisterKind("counter", func() actor.Receiver {
+		activations++
+		return &counter{}
+	}, actor.WithIdleTimeout(time.Minute))
+
+	a := d.Engine.GetGrain("counter", "a")
+	b := d.Engine.GetGrain("counter", "b")
+	is.Equal(actor.NewPID(actor.LocalAddress, "counter", "a"), a)
+
+	d.Engine.Send(context.Background(), a, increment{reply: probe.PID()})
+	d.Engine.Send(context.Background(), a, increment{reply: probe.PID()})
+	d.Engine.Send(context.Background(), b, increment{reply: probe.PID()})
+	d.RunUntilIdle()
+
+	probe.ExpectMsg(1)
+	probe.ExpectMsg(2)
+	probe.ExpectMsg(1) // every identity has its own activation
+	is.Equal(2, activations)
+
+	// passivated grains come back as a fresh instance from the kind
+	d.Advance(time.Minute)
+	d.Engine.Send(context.Background(), a, increment{reply: probe.PID()})
+	d.RunUntilIdle()
+	probe.ExpectMsg(1)
+	is.Equal(3, activations)
+
+	// unknown kinds still end up in the deadletter
+	d.Engine.Send(context.Background(), d.Engine.GetGrain("missing", "a"), increment{reply: probe.PID()})
+	d.RunUntilIdle()
+	probe.ExpectNoMsg(0)
+
+	d.Shutdown()
+}
+
+func TestWatchPassivatedGrain(t *testing.T) {
+	d := actortest.NewDeterministic()
+	probe := actortest.NewProbe(t, d.Engine)
+	watcher := actortest.NewProbe(t, d.Engine)
+
+	d.Engine.RegisterKind("counter", func() actor.Receiver { return &counter{} }, actor.WithIdleTimeout(time.Minute))
+	pid := d.Engine.GetGrain("counter", "a")
+
+	d.Engine.Send(context.Background(), pid, increment{reply: probe.PID()})
+	d.Advance(time.Minute)
+	probe.ExpectMsg(1)
+
+	// a passivated grain still exists, so watching it doesn't terminate
+	d.Engine.Watch(pid, watcher.PID())
+	d.RunUntilIdle()
+	watcher.ExpectNoMsg(0)
+
+	// the watch carries over to the next activation
+	d.Engine.Send(context.Background(), pid, increment{reply: probe.PID()})
+	d.RunUntilIdle()
+	probe.ExpectMsg(1)
+
+	d.Engine.Poison(pid, nil)
+	d.RunUntilIdle()
+	watcher.ExpectMsg(actor.Terminated{PID: pid})
+
+	d.Shutdown()
+}
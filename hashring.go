@@ -0,0 +1,150 @@
+package actor
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+const defaultVirtualNodes = 128
+
+// HashRing is a consistent hash ring that maps keys to members.
+// Each member is placed on the ring multiple times (virtual nodes) so that adding or removing a member only moves the keys that member owned.
+type HashRing struct {
+	mu       sync.RWMutex
+	replicas int
+	hashes   []uint64
+	owners   map[uint64]string
+	members  map[string][]uint64
+}
+
+// NewHashRing creates a new HashRing with the given number of virtual nodes per member.
+// A value <= 0 will use the default number of virtual nodes.
+func NewHashRing(virtualNodes int) *HashRing {
+	if virtualNodes <= 0 {
+		virtualNodes = defaultVirtualNodes
+	}
+
+	return &HashRing{
+		replicas: virtualNodes,
+		owners:   make(map[uint64]string),
+		members:  make(map[string][]uint64),
+	}
+}
+
+// Add members to the ring, members that already exist are ignored.
+func (r *HashRing) Add(members ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, member := range members {
+		if _, ok := r.members[member]; !ok {
+			r.members[member] = nil
+		}
+	}
+
+	r.rebuild()
+}
+
+// Remove members from the ring.
+func (r *HashRing) Remove(members ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, member := range members {
+		delete(r.members, member)
+	}
+
+	r.rebuild()
+}
+
+// rebuild places the members on the ring in sorted order, so rings with the same members are the same no matter the order they were added in, must be called while holding the lock.
+func (r *HashRing) rebuild() {
+	names := make([]string, 0, len(r.members))
+	for member := range r.members {
+		names = append(names, member)
+	}
+	sort.Strings(names)
+
+	r.owners = make(map[uint64]string, len(names)*r.replicas)
+	r.hashes = make([]uint64, 0, len(names)*r.replicas)
+
+	for _, member := range names {
+		nodes := make([]uint64, 0, r.replicas)
+		for i := 0; i < r.replicas; i++ {
+			// a virtual node that collides with another member's would take its keys, so salt it until it has a spot of its own
+			vnode := member + "#" + strconv.Itoa(i)
+			h := hashKey(vnode)
+			for salt := 1; r.taken(h); salt++ {
+				h = hashKey(vnode + "#" + strconv.Itoa(salt))
+			}
+
+			r.owners[h] = member
+			r.hashes = append(r.hashes, h)
+			nodes = append(nodes, h)
+		}
+		r.members[member] = nodes
+	}
+
+	sort.Slice(r.hashes, func(i, j int) bool { return r.hashes[i] < r.hashes[j] })
+}
+
+// Get returns the member that owns the given key, false will be returned if the ring is empty.
+func (r *HashRing) Get(key string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.hashes) == 0 {
+		return "", false
+	}
+
+	h := hashKey(key)
+	idx := sort.Search(len(r.hashes), func(i int) bool { return r.hashes[i] >= h })
+	if idx == len(r.hashes) {
+		idx = 0
+	}
+
+	return r.owners[r.hashes[idx]], true
+}
+
+// Members returns a sorted list of members on the ring.
+func (r *HashRing) Members() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	members := make([]string, 0, len(r.members))
+	for member := range r.members {
+		members = append(members, member)
+	}
+	sort.Strings(members)
+
+	return members
+}
+
+// Len returns the number of members on the ring.
+func (r *HashRing) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.members)
+}
+
+func (r *HashRing) taken(h uint64) bool {
+	_, ok := r.owners[h]
+	return ok
+}
+
+func hashKey(key string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+
+	// fnv alone clusters keys that only differ by their last few bytes (like virtual node suffixes), so finalize the hash to spread them around the ring
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+
+	return x
+}
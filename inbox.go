@@ -4,10 +4,21 @@ import (
 	"context"
 	"errors"
 	"sync"
+	"sync/atomic"
 )
 
 var (
 	ErrInboxClosed = errors.New("inbox closed")
+	ErrInboxFull   = errors.New("inbox full")
+)
+
+const (
+	inboxIdle int32 = iota
+	inboxScheduled
+)
+
+const (
+	systemInboxSize = 16
 )
 
 type Envelope struct {
@@ -15,56 +26,267 @@ type Envelope struct {
 	From    PID
 	Message any
 	Context context.Context
+
+	// seq of the message in the journal of the actor, 0 when it isn't journaled
+	seq uint64
 }
 
 type Inbox struct {
-	box       chan *Envelope
-	closeCh   chan struct{}
-	closeOnce sync.Once
-	startOnce sync.Once
-	wg        sync.WaitGroup
+	box        chan *Envelope
+	system     chan *Envelope
+	closeCh    chan struct{}
+	closeOnce  sync.Once
+	deliverMu  sync.RWMutex
+	startOnce  sync.Once
+	wg         sync.WaitGroup
+	dispatcher Dispatcher
+	throughput int
+	proc       Processor
+	status     atomic.Int32
+	started    atomic.Bool
+	suspended  atomic.Bool
+	stopped    atomic.Bool
+	rejectFull bool
+	// size is how many messages can be waiting when full inboxes are rejected, and queued how many are, messages the engine uses to control the actor don't count
+	size   int
+	queued atomic.Int32
+	// replayed messages from the journal, they are processed ahead of anything delivered
+	replayed chan *Envelope
 }
 
 func NewInbox(size int) *Inbox {
+	return newInbox(size, GoroutineDispatcher{}, defaultThroughput)
+}
+
+func newInbox(size int, dispatcher Dispatcher, throughput int) *Inbox {
 	in := &Inbox{}
-	in.box = make(chan *Envelope, size)
+	in.system = make(chan *Envelope, systemInboxSize)
 	in.closeCh = make(chan struct{})
+	in.dispatcher = dispatcher
+	in.throughput = throughput
+	in.size = size
+
+	if d, ok := dispatcher.(interface{ rejectsFullInbox() bool }); ok {
+		in.rejectFull = d.rejectsFullInbox()
+	}
+
+	// leave room for the messages that control the actor, so they don't take the place of the ones it receives
+	if in.rejectFull {
+		size += systemInboxSize
+	}
+	in.box = make(chan *Envelope, size)
+
 	return in
 }
 
+// Process starts processing messages in the inbox with the given Processor, this can only be called once.
 func (in *Inbox) Process(proc Processor) {
 	in.startOnce.Do(func() {
-		in.wg.Add(1)
-
-		go func() {
-			for env := range in.box {
-				proc.Process(env)
-			}
-			in.wg.Done()
-		}()
+		in.proc = proc
+		in.started.Store(true)
+		in.schedule()
 	})
 }
 
 func (in *Inbox) Deliver(env *Envelope) error {
+	// held while delivering, so closing can wait on anything in flight
+	in.deliverMu.RLock()
+	defer in.deliverMu.RUnlock()
+
 	select {
 	case <-in.closeCh:
 		return ErrInboxClosed
 
 	default:
-		in.box <- env
 	}
 
+	if in.dispatcherStopped() {
+		return ErrDispatcherStopped
+	}
+
+	// the system lane is processed ahead of everything else, even while suspended
+	if _, ok := env.Message.(stop); ok {
+		select {
+		case <-in.closeCh:
+			return ErrInboxClosed
+
+		case in.system <- env:
+		}
+	} else if in.rejectFull && !isSystemMessage(env.Message) {
+		if int(in.queued.Add(1)) > in.size {
+			in.queued.Add(-1)
+			return ErrInboxFull
+		}
+
+		select {
+		case <-in.closeCh:
+			in.queued.Add(-1)
+			return ErrInboxClosed
+
+		case in.box <- env:
+		}
+	} else {
+		select {
+		case <-in.closeCh:
+			return ErrInboxClosed
+
+		case in.box <- env:
+		}
+	}
+
+	in.schedule()
+
 	return nil
 }
 
+// replay puts messages ahead of anything delivered, however many there are, this must be done before the inbox can be delivered to.
+func (in *Inbox) replay(envs []*Envelope) {
+	in.replayed = make(chan *Envelope, len(envs))
+	for _, env := range envs {
+		in.replayed <- env
+	}
+}
+
+// isSystemMessage returns true for the messages the engine needs to control an actor, these are never rejected.
+func isSystemMessage(msg any) bool {
+	switch msg.(type) {
+	case initialize, poisonPill, passivate, stop:
+		return true
+	}
+	return false
+}
+
+// dispatcherStopped returns true when the dispatcher will no longer run the inbox.
+func (in *Inbox) dispatcherStopped() bool {
+	d, ok := in.dispatcher.(interface{ isStopped() bool })
+	return ok && d.isStopped()
+}
+
+// Close the inbox, no more messages will be delivered or processed.
+// Once closed, anything left in the inbox can be taken out with next without racing a delivery.
 func (in *Inbox) Close() {
+	in.close()
+	in.stopped.Store(true)
+}
+
+// Drain closes the inbox for delivery, and waits until all messages in it have been processed.
+func (in *Inbox) Drain() {
+	in.close()
+	in.wg.Wait()
+	in.stopped.Store(true)
+}
+
+func (in *Inbox) close() {
 	in.closeOnce.Do(func() {
 		close(in.closeCh)
-		close(in.box)
 	})
+
+	// wait for any delivery that got past the close check
+	in.deliverMu.Lock()
+	in.deliverMu.Unlock()
 }
 
-func (in *Inbox) Drain() {
-	in.Close()
-	in.wg.Wait()
+func (in *Inbox) len() int {
+	return len(in.box) + len(in.system) + len(in.replayed)
+}
+
+// idle returns true when there is nothing in the inbox, and nothing is being processed.
+func (in *Inbox) idle() bool {
+	return in.len() == 0 && in.status.Load() == inboxIdle
+}
+
+// suspend stops processing messages until resume is called, messages are still accepted while suspended.
+func (in *Inbox) suspend() {
+	in.suspended.Store(true)
+}
+
+func (in *Inbox) resume() {
+	in.suspended.Store(false)
+	in.schedule()
+}
+
+func (in *Inbox) schedule() {
+	if !in.started.Load() || in.stopped.Load() || (in.suspended.Load() && len(in.system) == 0) {
+		return
+	}
+
+	if in.status.CompareAndSwap(inboxIdle, inboxScheduled) {
+		in.wg.Add(1)
+
+		// a stopped dispatcher won't run the inbox
+		if d, ok := in.dispatcher.(interface{ trySchedule(func()) bool }); ok {
+			if !d.trySchedule(in.run) {
+				in.status.Store(inboxIdle)
+				in.wg.Done()
+			}
+			return
+		}
+
+		in.dispatcher.Schedule(in.run)
+	}
+}
+
+func (in *Inbox) run() {
+	defer in.wg.Done()
+
+	// only process up to throughput messages before giving the dispatcher a chance to run other inboxes
+	for processed := 0; !in.stopped.Load() && (in.throughput <= 0 || processed < in.throughput); processed++ {
+		env, ok := in.take()
+		if !ok {
+			break
+		}
+
+		in.proc.Process(env)
+	}
+
+	in.status.Store(inboxIdle)
+
+	// messages can be left when the throughput was reached, or they were delivered while we were still marked as running
+	if in.len() > 0 {
+		in.schedule()
+	}
+}
+
+// take the next message to process, only the system lane is processed while suspended.
+func (in *Inbox) take() (*Envelope, bool) {
+	select {
+	case env := <-in.system:
+		return env, true
+
+	default:
+	}
+
+	if in.suspended.Load() {
+		return nil, false
+	}
+
+	return in.next()
+}
+
+// next message in the inbox, the system lane first, then anything replayed.
+func (in *Inbox) next() (*Envelope, bool) {
+	select {
+	case env := <-in.system:
+		return env, true
+
+	default:
+	}
+
+	select {
+	case env := <-in.replayed:
+		return env, true
+
+	default:
+	}
+
+	select {
+	case env := <-in.box:
+		if in.rejectFull && !isSystemMessage(env.Message) {
+			in.queued.Add(-1)
+		}
+		return env, true
+
+	default:
+		return nil, false
+	}
 }
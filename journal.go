@@ -0,0 +1,81 @@
+package actor
+
+// Journal makes the inbox of an actor durable, so messages that were sent to it aren't lost when the process stops before they were processed.
+// Messages are appended to the journal before they are delivered, and marked as processed once the actor has processed them without panicking.
+// The messages that were never processed are replayed when the actor is spawned, ahead of anything sent to it.
+// Messages left in the inbox when the actor stops are kept in the journal instead of being deadlettered, as are messages that couldn't be delivered.
+type Journal interface {
+	// Append a message that is about to be delivered, returning its sequence number which must be greater than 0.
+	// The message is not delivered if an error is returned.
+	Append(to PID, from PID, msg any) (uint64, error)
+	// Processed marks the message with the sequence number as processed.
+	Processed(seq uint64) error
+	// Replay returns the messages that weren't processed when the journal was opened, in the order they were appended.
+	// Only the first call returns them, so an actor that is activated again doesn't receive them twice.
+	Replay() ([]JournalEntry, error)
+}
+
+// JournalEntry is a message in a Journal.
+type JournalEntry struct {
+	Seq     uint64
+	To      PID
+	From    PID
+	Message any
+}
+
+// journal appends the envelope to the journal of the actor, messages the engine uses to control the actor are not journaled.
+func (p *processor) journal(env *Envelope) error {
+	if p.options.Journal == nil || isSystemMessage(env.Message) {
+		return nil
+	}
+
+	seq, err := p.options.Journal.Append(env.To, env.From, env.Message)
+	if err != nil {
+		return err
+	}
+	env.seq = seq
+
+	return nil
+}
+
+// processed marks a journaled envelope as processed, it is no longer replayed.
+func (p *processor) processed(env *Envelope) {
+	if env.seq == 0 || p.options.Journal == nil {
+		return
+	}
+
+	if err := p.options.Journal.Processed(env.seq); err != nil {
+		p.context.logger.Error("Failed to mark journaled message as processed.", "seq", env.seq, "err", err)
+	}
+	env.seq = 0
+}
+
+// replay puts the messages from the journal that weren't processed in the inbox, before the actor is registered so nothing sent to it gets ahead of them.
+// They are never rejected by a full inbox, and stay in the journal if the actor turns out to be a duplicate.
+func (p *processor) replay() {
+	if p.options.Journal == nil {
+		return
+	}
+
+	entries, err := p.options.Journal.Replay()
+	if err != nil {
+		p.context.logger.Error("Failed to replay journal.", "err", err)
+		return
+	}
+
+	envs := make([]*Envelope, len(entries))
+	for i, entry := range entries {
+		env := envelopePool.Get().(*Envelope)
+		env.To = entry.To
+		env.From = entry.From
+		env.Message = entry.Message
+		env.Context = p.context.engine.options.Context
+		env.seq = entry.Seq
+		envs[i] = env
+	}
+	p.inbox.replay(envs)
+
+	if len(entries) > 0 {
+		p.context.logger.Info("Replayed journal.", "messages", len(entries))
+	}
+}
@@ -0,0 +1,170 @@
+package actor_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/renevo/actor"
+)
+
+// memoryJournal keeps the journal in memory, replaying the messages it was created with.
+type memoryJournal struct {
+	mu        sync.Mutex
+	seq       uint64
+	entries   map[uint64]actor.JournalEntry
+	replay    []actor.JournalEntry
+	onReplay  func()
+	processed []uint64
+}
+
+func newMemoryJournal(to actor.PID, msgs ...any) *memoryJournal {
+	j := &memoryJournal{entries: make(map[uint64]actor.JournalEntry)}
+	for _, msg := range msgs {
+		j.seq++
+		entry := actor.JournalEntry{Seq: j.seq, To: to, Message: msg}
+		j.entries[j.seq] = entry
+		j.replay = append(j.replay, entry)
+	}
+	return j
+}
+
+func (j *memoryJournal) Append(to actor.PID, from actor.PID, msg any) (uint64, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.seq++
+	j.entries[j.seq] = actor.JournalEntry{Seq: j.seq, To: to, From: from, Message: msg}
+	return j.seq, nil
+}
+
+func (j *memoryJournal) Processed(seq uint64) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	delete(j.entries, seq)
+	j.processed = append(j.processed, seq)
+	return nil
+}
+
+func (j *memoryJournal) Replay() ([]actor.JournalEntry, error) {
+	if j.onReplay != nil {
+		j.onReplay()
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entries := j.replay
+	j.replay = nil
+	return entries, nil
+}
+
+func (j *memoryJournal) len() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return len(j.entries)
+}
+
+// collect receives strings on a channel.
+func collect(received chan<- string) actor.ReceiverFunc {
+	return func(ctx *actor.Context) {
+		if msg, ok := ctx.Message().(string); ok {
+			received <- msg
+		}
+	}
+}
+
+func expectStrings(t *testing.T, received <-chan string, expected ...string) {
+	t.Helper()
+
+	for _, want := range expected {
+		select {
+		case got := <-received:
+			if got != want {
+				t.Fatalf("received %q, expected %q", got, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for %q", want)
+		}
+	}
+}
+
+func TestReplayBeforeSend(t *testing.T) {
+	is := is.New(t)
+
+	metrics := &deadlettered{Metrics: actor.NewPrometheusMetrics(), pids: make(chan actor.PID, 16)}
+	engine := actor.NewEngine(actor.WithMetrics(metrics))
+	t.Cleanup(engine.ShutdownAndWait)
+
+	pid := actor.NewPID(engine.Address(), "TestReplayBeforeSend")
+	journal := newMemoryJournal(pid, "a", "b")
+
+	// sent while the journal is replaying, before the actor exists
+	journal.onReplay = func() {
+		engine.Send(context.Background(), pid, "early")
+	}
+
+	received := make(chan string, 8)
+	engine.Spawn(collect(received), "TestReplayBeforeSend", actor.WithJournal(journal))
+	engine.Send(context.Background(), pid, "c")
+
+	expectStrings(t, received, "a", "b", "c") // replayed messages come first
+	is.Equal(pid, <-metrics.pids)             // the early message had nobody to go to
+}
+
+func TestReplayFullInbox(t *testing.T) {
+	is := is.New(t)
+
+	dispatcher := actor.NewPoolDispatcher(1)
+	engine := actor.NewEngine()
+
+	pid := actor.NewPID(engine.Address(), "TestReplayFullInbox")
+	journal := newMemoryJournal(pid, "a", "b", "c", "d", "e")
+
+	received := make(chan string, 8)
+	engine.Spawn(collect(received), "TestReplayFullInbox", actor.WithJournal(journal), actor.WithDispatcher(dispatcher), actor.WithInboxSize(1))
+
+	expectStrings(t, received, "a", "b", "c", "d", "e") // more than fit in the inbox, none are rejected
+
+	engine.ShutdownAndWait()
+	dispatcher.Stop()
+	is.Equal(0, journal.len())
+}
+
+func TestUndeliveredStaysJournaled(t *testing.T) {
+	is := is.New(t)
+
+	dispatcher := actor.NewPoolDispatcher(1)
+	engine := actor.NewEngine()
+
+	pid := actor.NewPID(engine.Address(), "TestUndeliveredStaysJournaled")
+	journal := newMemoryJournal(pid)
+
+	started := make(chan struct{})
+	gate := make(chan struct{})
+	engine.SpawnFunc(func(ctx *actor.Context) {
+		if ctx.Message() == "a" {
+			close(started)
+			<-gate
+		}
+	}, "TestUndeliveredStaysJournaled", actor.WithJournal(journal), actor.WithDispatcher(dispatcher), actor.WithInboxSize(1))
+
+	engine.Send(context.Background(), pid, "a")
+	<-started
+	engine.Send(context.Background(), pid, "b")
+	engine.Send(context.Background(), pid, "c") // the inbox is full, so it is deadlettered
+
+	close(gate)
+	engine.ShutdownAndWait()
+	dispatcher.Stop()
+
+	// a was processed, c is kept to be replayed
+	journal.mu.Lock()
+	defer journal.mu.Unlock()
+	_, kept := journal.entries[3]
+	is.True(kept)
+	is.Equal([]uint64{1, 2}, journal.processed)
+}
@@ -0,0 +1,215 @@
+package actor
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metrics records actor metrics, all metrics are labelled by the actor PID and its tag, which is everything in the PID after the name of the actor.
+// Implementations must be safe for concurrent use, as every actor will report on its own goroutine.
+type Metrics interface {
+	InboxDepth(pid PID, tag string, depth int)
+	MessageProcessed(pid PID, tag string, latency time.Duration)
+	Panic(pid PID, tag string)
+	Restart(pid PID, tag string)
+	Deadletter(pid PID, tag string)
+}
+
+type nopMetrics struct{}
+
+func (nopMetrics) InboxDepth(PID, string, int)                 {}
+func (nopMetrics) MessageProcessed(PID, string, time.Duration) {}
+func (nopMetrics) Panic(PID, string)                           {}
+func (nopMetrics) Restart(PID, string)                         {}
+func (nopMetrics) Deadletter(PID, string)                      {}
+
+var (
+	defaultLatencyBuckets = []float64{0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5}
+)
+
+type metricLabels struct {
+	pid string
+	tag string
+}
+
+type actorMetrics struct {
+	inboxDepth   int
+	processed    uint64
+	panics       uint64
+	restarts     uint64
+	deadletters  uint64
+	latencySum   float64
+	latencyCount uint64
+	buckets      []uint64
+}
+
+// PrometheusMetrics is a Metrics implementation that exports all metrics in the Prometheus text format.
+// Metrics are labelled by tag only, unless WithPIDLabels is used, so the inbox depth of a tag is the total of all actors with that tag.
+type PrometheusMetrics struct {
+	mu      sync.Mutex
+	buckets []float64
+	series  map[metricLabels]*actorMetrics
+	pids    bool
+	// depths of the inboxes that aren't empty, when labelled by tag only
+	depths map[PID]int
+}
+
+// NewPrometheusMetrics creates a new PrometheusMetrics with the given latency histogram buckets in seconds.
+// When no buckets are provided, a default set from 100µs to 5s will be used.
+func NewPrometheusMetrics(buckets ...float64) *PrometheusMetrics {
+	if len(buckets) == 0 {
+		buckets = defaultLatencyBuckets
+	}
+
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+
+	return &PrometheusMetrics{
+		buckets: sorted,
+		series:  make(map[metricLabels]*actorMetrics),
+		depths:  make(map[PID]int),
+	}
+}
+
+// WithPIDLabels labels all metrics by PID as well as tag.
+// Every PID gets its own series that is kept for the life of the PrometheusMetrics, so this should only be used when the set of actors is bounded.
+func (m *PrometheusMetrics) WithPIDLabels() *PrometheusMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pids = true
+	return m
+}
+
+func (m *PrometheusMetrics) get(pid PID, tag string) *actorMetrics {
+	labels := metricLabels{tag: tag}
+	if m.pids {
+		labels.pid = pid.String()
+	}
+	s, ok := m.series[labels]
+	if !ok {
+		s = &actorMetrics{buckets: make([]uint64, len(m.buckets))}
+		m.series[labels] = s
+	}
+	return s
+}
+
+func (m *PrometheusMetrics) InboxDepth(pid PID, tag string, depth int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.pids {
+		m.get(pid, tag).inboxDepth = depth
+		return
+	}
+
+	// every actor with the tag shares the series, so it holds their total
+	m.get(pid, tag).inboxDepth += depth - m.depths[pid]
+	if depth == 0 {
+		delete(m.depths, pid)
+	} else {
+		m.depths[pid] = depth
+	}
+}
+
+func (m *PrometheusMetrics) MessageProcessed(pid PID, tag string, latency time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := m.get(pid, tag)
+	s.processed++
+	s.latencyCount++
+	s.latencySum += latency.Seconds()
+	for i, bucket := range m.buckets {
+		if latency.Seconds() <= bucket {
+			s.buckets[i]++
+		}
+	}
+}
+
+func (m *PrometheusMetrics) Panic(pid PID, tag string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.get(pid, tag).panics++
+}
+
+func (m *PrometheusMetrics) Restart(pid PID, tag string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.get(pid, tag).restarts++
+}
+
+func (m *PrometheusMetrics) Deadletter(pid PID, tag string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.get(pid, tag).deadletters++
+}
+
+// WriteTo writes all metrics in the Prometheus text exposition format.
+func (m *PrometheusMetrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	labels := make([]metricLabels, 0, len(m.series))
+	for l := range m.series {
+		labels = append(labels, l)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		if labels[i].pid == labels[j].pid {
+			return labels[i].tag < labels[j].tag
+		}
+		return labels[i].pid < labels[j].pid
+	})
+
+	sb := &strings.Builder{}
+
+	writeMetric := func(name, kind, help string, value func(*actorMetrics) string) {
+		fmt.Fprintf(sb, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+		for _, l := range labels {
+			fmt.Fprintf(sb, "%s{%s} %s\n", name, l.String(), value(m.series[l]))
+		}
+	}
+
+	writeMetric("actor_inbox_depth", "gauge", "Number of messages waiting in the actor inbox.", func(s *actorMetrics) string { return strconv.Itoa(s.inboxDepth) })
+	writeMetric("actor_messages_processed_total", "counter", "Number of messages processed by the actor.", func(s *actorMetrics) string { return strconv.FormatUint(s.processed, 10) })
+	writeMetric("actor_panics_total", "counter", "Number of panics recovered from the actor.", func(s *actorMetrics) string { return strconv.FormatUint(s.panics, 10) })
+	writeMetric("actor_restarts_total", "counter", "Number of times the actor was restarted.", func(s *actorMetrics) string { return strconv.FormatUint(s.restarts, 10) })
+	writeMetric("actor_deadletters_total", "counter", "Number of messages sent to the actor that ended up in the deadletter.", func(s *actorMetrics) string { return strconv.FormatUint(s.deadletters, 10) })
+
+	name := "actor_message_latency_seconds"
+	fmt.Fprintf(sb, "# HELP %s %s\n# TYPE %s histogram\n", name, "Time taken by the actor to process a message.", name)
+	for _, l := range labels {
+		s := m.series[l]
+		for i, bucket := range m.buckets {
+			fmt.Fprintf(sb, "%s_bucket{%s,le=\"%s\"} %d\n", name, l.String(), strconv.FormatFloat(bucket, 'g', -1, 64), s.buckets[i])
+		}
+		fmt.Fprintf(sb, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, l.String(), s.latencyCount)
+		fmt.Fprintf(sb, "%s_sum{%s} %s\n", name, l.String(), strconv.FormatFloat(s.latencySum, 'g', -1, 64))
+		fmt.Fprintf(sb, "%s_count{%s} %d\n", name, l.String(), s.latencyCount)
+	}
+
+	n, err := io.WriteString(w, sb.String())
+	return int64(n), err
+}
+
+// ServeHTTP writes all metrics in the Prometheus text exposition format, so PrometheusMetrics can be used as a scrape endpoint.
+func (m *PrometheusMetrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	_, _ = m.WriteTo(w)
+}
+
+func (l metricLabels) String() string {
+	if l.pid == "" {
+		return `tag="` + escapeLabel(l.tag) + `"`
+	}
+	return `pid="` + escapeLabel(l.pid) + `",tag="` + escapeLabel(l.tag) + `"`
+}
+
+func escapeLabel(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
@@ -0,0 +1,144 @@
+package actor_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/renevo/actor"
+)
+
+func TestPrometheusMetrics(t *testing.T) {
+	is := is.New(t)
+
+	metrics := actor.NewPrometheusMetrics().WithPIDLabels()
+	engine := actor.NewEngine(actor.WithMetrics(metrics))
+
+	var panicked bool
+	pid := engine.SpawnFunc(func(ctx *actor.Context) {
+		if _, ok := ctx.Message().(string); ok {
+			if !panicked {
+				panicked = true
+				panic("boom")
+			}
+			ctx.Respond("ok")
+		}
+	}, "TestPrometheusMetrics", actor.WithTags("worker"), actor.WithRestartDelay(time.Millisecond))
+
+	engine.Send(context.Background(), pid, "panic")
+	_, err := engine.Request(pid, "hello", time.Second)
+	is.NoErr(err)
+
+	_, err = engine.Request(actor.NewPID(actor.LocalAddress, "missing", "gone"), "hello", 10*time.Millisecond)
+	is.True(err != nil) // request to missing actor should time out
+
+	engine.ShutdownAndWait()
+
+	sb := &strings.Builder{}
+	_, err = metrics.WriteTo(sb)
+	is.NoErr(err)
+	out := sb.String()
+
+	labels := `{pid="local.TestPrometheusMetrics.worker",tag="worker"}`
+	is.True(strings.Contains(out, "actor_messages_processed_total"+labels+" 1"))
+	is.True(strings.Contains(out, "actor_panics_total"+labels+" 1"))
+	is.True(strings.Contains(out, "actor_restarts_total"+labels+" 1"))
+	is.True(strings.Contains(out, "actor_message_latency_seconds_count"+labels+" 1"))
+	is.True(strings.Contains(out, `actor_message_latency_seconds_bucket{pid="local.TestPrometheusMetrics.worker",tag="worker",le="+Inf"} 1`))
+	is.True(strings.Contains(out, `actor_deadletters_total{pid="local.missing.gone",tag="gone"} 1`))
+	is.True(strings.Contains(out, "# TYPE actor_inbox_depth gauge"))
+}
+
+func TestPrometheusMetricsByTag(t *testing.T) {
+	is := is.New(t)
+
+	metrics := actor.NewPrometheusMetrics()
+	engine := actor.NewEngine(actor.WithMetrics(metrics))
+
+	for _, name := range []string{"a", "b"} {
+		pid := engine.SpawnFunc(func(ctx *actor.Context) {
+			if _, ok := ctx.Message().(string); ok {
+				ctx.Respond("ok")
+			}
+		}, name, actor.WithTags("worker"))
+
+		_, err := engine.Request(pid, "hello", time.Second)
+		is.NoErr(err)
+	}
+
+	engine.ShutdownAndWait()
+
+	sb := &strings.Builder{}
+	_, err := metrics.WriteTo(sb)
+	is.NoErr(err)
+	out := sb.String()
+
+	is.True(strings.Contains(out, `actor_messages_processed_total{tag="worker"} 2`))
+	is.True(!strings.Contains(out, `pid=`)) // pids are only labelled when asked for
+}
+
+func TestPrometheusMetricsInboxDepthByTag(t *testing.T) {
+	is := is.New(t)
+
+	metrics := actor.NewPrometheusMetrics()
+	a := actor.NewPID(actor.LocalAddress, "a", "worker")
+	b := actor.NewPID(actor.LocalAddress, "b", "worker")
+
+	depth := func() string {
+		sb := &strings.Builder{}
+		_, err := metrics.WriteTo(sb)
+		is.NoErr(err)
+		for _, line := range strings.Split(sb.String(), "\n") {
+			if value, ok := strings.CutPrefix(line, `actor_inbox_depth{tag="worker"} `); ok {
+				return value
+			}
+		}
+		return ""
+	}
+
+	metrics.InboxDepth(a, "worker", 2)
+	metrics.InboxDepth(b, "worker", 3)
+	is.Equal("5", depth()) // the total of every actor with the tag
+
+	metrics.InboxDepth(a, "worker", 0)
+	is.Equal("3", depth())
+}
+
+func TestPrometheusMetricsChildTag(t *testing.T) {
+	is := is.New(t)
+
+	metrics := actor.NewPrometheusMetrics()
+	engine := actor.NewEngine(actor.WithMetrics(metrics))
+
+	parent := engine.SpawnFunc(func(ctx *actor.Context) {
+		if _, ok := ctx.Message().(string); ok {
+			child := ctx.SpawnFunc(func(ctx *actor.Context) {
+				if _, ok := ctx.Message().(string); ok {
+					ctx.Respond("ok")
+				}
+			}, "child")
+			ctx.Respond(child)
+		}
+	}, "parent", actor.WithTags("1"))
+
+	child, err := engine.Request(parent, "spawn", time.Second)
+	is.NoErr(err)
+	_, err = engine.Request(child.(actor.PID), "hello", time.Second)
+	is.NoErr(err)
+
+	<-engine.Poison(child.(actor.PID), nil)
+	engine.Send(context.Background(), child.(actor.PID), "hello")
+
+	engine.ShutdownAndWait()
+
+	sb := &strings.Builder{}
+	_, err = metrics.WriteTo(sb)
+	is.NoErr(err)
+	out := sb.String()
+
+	// what the child processed and what was sent to it once it stopped are in the same series
+	is.True(strings.Contains(out, `actor_messages_processed_total{tag="1.child"} 1`))
+	is.True(strings.Contains(out, `actor_deadletters_total{tag="1.child"} 1`))
+}
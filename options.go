@@ -9,6 +9,7 @@ import (
 const (
 	defaultInboxSize   = 1024
 	defaultMaxRestarts = 3
+	defaultThroughput  = 300
 )
 
 var (
@@ -16,15 +17,26 @@ var (
 )
 
 type Options struct {
-	Name         string
-	Receiver     Receiver
-	InboxSize    int
-	MaxRestarts  int
-	RestartDelay time.Duration
-	Middleware   []Middleware
-	Tags         []string
-	Context      context.Context
-	Logger       *slog.Logger
+	Name              string
+	Receiver          Receiver
+	Producer          Producer
+	InboxSize         int
+	MaxRestarts       int
+	RestartDelay      time.Duration
+	RecreateOnRestart bool
+	Middleware        []Middleware
+	Tags              []string
+	Context           context.Context
+	Logger            *slog.Logger
+	Metrics           Metrics
+	Dispatcher        Dispatcher
+	Throughput        int
+	Clock             Clock
+	IdleTimeout       time.Duration
+	Remote            Remote
+	SpawnPolicy       SpawnPolicy
+	ShutdownTimeout   time.Duration
+	Journal           Journal
 }
 
 type Option func(*Options)
@@ -37,6 +49,10 @@ func copyOptions(source *Options, receiver Receiver) *Options {
 		RestartDelay: source.RestartDelay,
 		Context:      source.Context,
 		Logger:       source.Logger,
+		Metrics:      source.Metrics,
+		Dispatcher:   source.Dispatcher,
+		Throughput:   source.Throughput,
+		Clock:        source.Clock,
 	}
 }
 
@@ -87,3 +103,102 @@ func WithLogger(logger *slog.Logger) Option {
 		opt.Logger = logger
 	}
 }
+
+func WithMetrics(metrics Metrics) Option {
+	return func(opt *Options) {
+		if metrics == nil {
+			metrics = nopMetrics{}
+		}
+		opt.Metrics = metrics
+	}
+}
+
+func WithDispatcher(dispatcher Dispatcher) Option {
+	return func(opt *Options) {
+		if dispatcher == nil {
+			dispatcher = GoroutineDispatcher{}
+		}
+		opt.Dispatcher = dispatcher
+	}
+}
+
+// WithThroughput sets the maximum number of messages an actor processes per turn on its dispatcher, n <= 0 processes until the inbox is empty.
+func WithThroughput(n int) Option {
+	return func(opt *Options) {
+		opt.Throughput = n
+	}
+}
+
+// WithIdleTimeout passivates the actor when it hasn't received a message for the given duration.
+// A passivated actor is stopped and removed from the registry, and spawned again with a new receiver from its Producer when a message is sent to its PID.
+// The idle timeout is ignored for actors without a Producer.
+func WithIdleTimeout(d time.Duration) Option {
+	return func(opt *Options) {
+		opt.IdleTimeout = d
+	}
+}
+
+// WithProducer sets the Producer used to create a new receiver for the actor when it is reactivated.
+func WithProducer(producer Producer) Option {
+	return func(opt *Options) {
+		opt.Producer = producer
+	}
+}
+
+// WithRecreateOnRestart creates a new receiver for the actor from its Producer when it is restarted, instead of reusing the receiver that panicked.
+// It is ignored for actors without a Producer.
+func WithRecreateOnRestart() Option {
+	return func(opt *Options) {
+		opt.RecreateOnRestart = true
+	}
+}
+
+// WithJournal makes the inbox of the actor durable with the given Journal, each actor needs its own Journal.
+func WithJournal(journal Journal) Option {
+	return func(opt *Options) {
+		opt.Journal = journal
+	}
+}
+
+// SpawnPolicy decides what happens when an actor is spawned with the same ID as an existing actor.
+type SpawnPolicy byte
+
+const (
+	// SpawnPolicyFail doesn't spawn the actor, TrySpawn returns ErrDuplicateActor.
+	SpawnPolicyFail SpawnPolicy = iota
+	// SpawnPolicyReplace poisons the existing actor, and waits for it to stop before spawning the new one.
+	// Replacing an actor from its own goroutine will deadlock, as it can't stop while it is waiting.
+	SpawnPolicyReplace
+	// SpawnPolicyExisting doesn't spawn the actor, and returns the PID of the existing one without an error.
+	SpawnPolicyExisting
+)
+
+// WithSpawnPolicy sets what happens when an actor is spawned with the same ID as an existing actor, the default is SpawnPolicyFail.
+func WithSpawnPolicy(policy SpawnPolicy) Option {
+	return func(opt *Options) {
+		opt.SpawnPolicy = policy
+	}
+}
+
+// WithShutdownTimeout limits how long each phase of ShutdownContext can take before the actors that haven't finished are force stopped, this is only used by NewEngine.
+func WithShutdownTimeout(d time.Duration) Option {
+	return func(opt *Options) {
+		opt.ShutdownTimeout = d
+	}
+}
+
+// WithRemote connects the engine to other engines, this is only used by NewEngine.
+func WithRemote(remote Remote) Option {
+	return func(opt *Options) {
+		opt.Remote = remote
+	}
+}
+
+func WithClock(clock Clock) Option {
+	return func(opt *Options) {
+		if clock == nil {
+			clock = realClock{}
+		}
+		opt.Clock = clock
+	}
+}
@@ -0,0 +1,137 @@
+package actor
+
+import (
+	"context"
+	"time"
+)
+
+type passivate struct{}
+
+// passivation holds everything needed to bring a passivated actor back when it is sent a message.
+// Grains are brought back from their kind, so their entries only hold watchers.
+type passivation struct {
+	options  *Options
+	parent   *Context
+	watchers map[string]PID
+}
+
+func (e *Engine) activate(pid PID) Processor {
+	e.activateMu.Lock()
+	defer e.activateMu.Unlock()
+
+	// someone else may have beat us to it
+	if proc := e.registry.get(pid); proc != nil {
+		return proc
+	}
+
+	entry, ok := e.passivated.Get(pid.ID)
+	if !ok {
+		return e.activateGrain(pid, nil)
+	}
+	e.passivated.Delete(pid.ID)
+
+	if entry.options == nil {
+		return e.activateGrain(pid, entry.watchers)
+	}
+
+	proc := newProcessor(e, entry.options)
+	proc.context.parentContext = entry.parent
+	proc.watchers = entry.watchers
+	e.SpawnProcessor(proc)
+
+	return proc
+}
+
+// forget a passivated actor, so it won't be activated again, watchers will be notified that it has terminated.
+func (e *Engine) forget(pid PID) bool {
+	e.activateMu.Lock()
+	entry, ok := e.passivated.Get(pid.ID)
+	e.passivated.Delete(pid.ID)
+	e.activateMu.Unlock()
+
+	if !ok {
+		return false
+	}
+
+	for _, watcher := range entry.watchers {
+		e.send(e.options.Context, watcher, Terminated{PID: pid}, pid)
+	}
+
+	return true
+}
+
+func (e *Engine) watchPassivated(target PID, watcher PID) bool {
+	e.activateMu.Lock()
+	defer e.activateMu.Unlock()
+
+	entry, ok := e.passivated.Get(target.ID)
+	if !ok {
+		if !e.isGrain(target) {
+			return false
+		}
+
+		// grains always exist, so they are watched without being activated
+		entry = &passivation{}
+		e.passivated.Set(target.ID, entry)
+	}
+
+	if entry.watchers == nil {
+		entry.watchers = make(map[string]PID)
+	}
+	entry.watchers[watcher.String()] = watcher
+
+	return true
+}
+
+func (p *processor) armIdleTimer(d time.Duration) {
+	p.idleTimer = p.options.Clock.AfterFunc(d, func() {
+		env := envelopePool.Get().(*Envelope)
+		env.To = p.pid
+		env.From = p.pid
+		env.Message = passivate{}
+		env.Context = context.Background()
+
+		// the actor may already be gone, in which case there is nothing to passivate
+		if err := p.inbox.Deliver(env); err != nil {
+			envelopePool.Put(env)
+		}
+	})
+}
+
+func (p *processor) passivate() {
+	idle := p.options.Clock.Now().Sub(p.lastActivity)
+	if idle < p.options.IdleTimeout || p.inbox.len() > 0 {
+		p.armIdleTimer(p.options.IdleTimeout - idle)
+		return
+	}
+
+	p.context.logger.Debug("Actor passivating.", "idle", idle)
+
+	// the receiver isn't kept, so none of its state is held on to while passivated
+	options := *p.options
+	options.Receiver = nil
+
+	p.watchMu.Lock()
+	entry := &passivation{
+		options:  &options,
+		parent:   p.context.parentContext,
+		watchers: p.watchers,
+	}
+	p.watchers = nil
+	p.watchMu.Unlock()
+
+	engine := p.context.engine
+	engine.activateMu.Lock()
+	// grains can always be activated again from their kind, so they only need an entry to hold on to their watchers
+	if p.grain {
+		entry.options = nil
+		entry.parent = nil
+	}
+	if !p.grain || len(entry.watchers) > 0 {
+		engine.passivated.Set(p.pid.ID, entry)
+	}
+	p.passivated.Store(true)
+	engine.activateMu.Unlock()
+
+	p.cleanup(nil)
+}
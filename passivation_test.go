@@ -0,0 +1,159 @@
+package actor_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/renevo/actor"
+	"github.com/renevo/actor/actortest"
+)
+
+func TestIdlePassivation(t *testing.T) {
+	is := is.New(t)
+
+	d := actortest.NewDeterministic()
+	var started, stopped, received, instanceReceived int
+
+	producer := func() actor.Receiver {
+		count := 0
+		return actor.ReceiverFunc(func(ctx *actor.Context) {
+			switch ctx.Message().(type) {
+			case actor.Started:
+				started++
+			case actor.Stopped:
+				stopped++
+			case string:
+				received++
+				count++
+				instanceReceived = count
+			}
+		})
+	}
+
+	pid := d.Engine.Spawn(producer(), "TestIdlePassivation", actor.WithProducer(producer), actor.WithIdleTimeout(time.Minute))
+	d.RunUntilIdle()
+	is.Equal(1, started)
+
+	// activity keeps the actor alive
+	d.Advance(45 * time.Second)
+	d.Engine.Send(context.Background(), pid, "keep alive")
+	d.Advance(45 * time.Second)
+	is.Equal(0, stopped)
+
+	d.Advance(15 * time.Second)
+	is.Equal(1, stopped)                                  // passivated after being idle
+	is.Equal(pid, d.Engine.GetPID("TestIdlePassivation")) // still addressable
+
+	d.Engine.Send(context.Background(), pid, "wake up")
+	d.RunUntilIdle()
+	is.Equal(2, started) // reactivated by the message
+	is.Equal(2, received)
+	is.Equal(1, instanceReceived) // reactivated with a new receiver
+
+	d.Advance(time.Minute)
+	is.Equal(2, stopped)
+
+	d.Shutdown()
+}
+
+func TestPassivatedPoison(t *testing.T) {
+	d := actortest.NewDeterministic()
+	probe := actortest.NewProbe(t, d.Engine)
+
+	producer := func() actor.Receiver { return actor.ReceiverFunc(func(ctx *actor.Context) {}) }
+	pid := d.Engine.Spawn(producer(), "TestPassivatedPoison", actor.WithProducer(producer), actor.WithIdleTimeout(time.Second))
+	d.Advance(time.Second)
+
+	// watching a passivated actor doesn't terminate right away, poisoning it does
+	d.Engine.Watch(pid, probe.PID())
+	d.RunUntilIdle()
+	probe.ExpectNoMsg(0)
+
+	d.Engine.Poison(pid, nil)
+	d.RunUntilIdle()
+	probe.ExpectMsg(actor.Terminated{PID: pid})
+
+	d.Shutdown()
+}
+
+func TestIdleTimeoutWithoutProducer(t *testing.T) {
+	is := is.New(t)
+
+	d := actortest.NewDeterministic()
+	pid := d.Engine.SpawnFunc(func(ctx *actor.Context) {}, "TestIdleTimeoutWithoutProducer", actor.WithIdleTimeout(time.Second))
+	d.Advance(time.Minute)
+
+	is.Equal(pid, d.Engine.GetPID("TestIdleTimeoutWithoutProducer")) // not passivated without a producer
+
+	d.Shutdown()
+}
+
+// overlapping counts the activations of an actor running at the same time, which should never be more than one.
+type overlapping struct {
+	active  atomic.Int32
+	overlap atomic.Bool
+	stopped chan struct{}
+}
+
+func (o *overlapping) producer() actor.Receiver {
+	return actor.ReceiverFunc(func(ctx *actor.Context) {
+		switch ctx.Message().(type) {
+		case actor.Started:
+			if o.active.Add(1) > 1 {
+				o.overlap.Store(true)
+			}
+
+		case actor.Stopped:
+			select {
+			case o.stopped <- struct{}{}:
+			default:
+			}
+
+			// give a new activation the chance to start while this one is still stopping
+			time.Sleep(5 * time.Millisecond)
+			o.active.Add(-1)
+		}
+	})
+}
+
+func (o *overlapping) run(t *testing.T, engine *actor.Engine, pid actor.PID) {
+	t.Helper()
+
+	for i := 0; i < 50 && !o.overlap.Load(); i++ {
+		<-o.stopped
+
+		// the actor is passivating, wake it up while its Stopped handler runs
+		for j := 0; j < 10; j++ {
+			engine.Send(context.Background(), pid, "wake up")
+		}
+	}
+
+	if o.overlap.Load() {
+		t.Fatal("a new activation started before the passivated one had stopped")
+	}
+}
+
+func TestPassivationOverlap(t *testing.T) {
+	engine := actor.NewEngine()
+	t.Cleanup(engine.ShutdownAndWait)
+
+	o := &overlapping{stopped: make(chan struct{}, 1)}
+	pid := engine.Spawn(o.producer(), "TestPassivationOverlap", actor.WithProducer(o.producer), actor.WithIdleTimeout(time.Millisecond))
+
+	o.run(t, engine, pid)
+}
+
+func TestGrainPassivationOverlap(t *testing.T) {
+	engine := actor.NewEngine()
+	t.Cleanup(engine.ShutdownAndWait)
+
+	o := &overlapping{stopped: make(chan struct{}, 1)}
+	engine.RegisterKind("overlapping", o.producer, actor.WithIdleTimeout(time.Millisecond))
+	pid := engine.GetGrain("overlapping", "a")
+	engine.Send(context.Background(), pid, "activate")
+
+	o.run(t, engine, pid)
+}
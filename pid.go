@@ -30,6 +30,12 @@ func (p PID) Child(name string, tags ...string) PID {
 	return NewPID(p.Address, p.ID, append([]string{name}, tags...)...)
 }
 
+// pidTag returns everything in the PID after its name, which is the tags for actors that are not children, and what metrics are labelled with.
+func pidTag(pid PID) string {
+	_, tag, _ := strings.Cut(pid.ID, pidSeparator)
+	return tag
+}
+
 func (p PID) IsZero() bool {
 	return p.Address == "" && p.ID == ""
 }
@@ -2,8 +2,10 @@ package actor
 
 import (
 	"context"
+	"errors"
 	"reflect"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -33,23 +35,51 @@ type Processor interface {
 }
 
 type processor struct {
-	options  *Options
-	inbox    *Inbox
-	context  *Context
-	pid      PID
-	restarts int
-	state    processorState
-	init     sync.Once
+	options   *Options
+	inbox     *Inbox
+	context   *Context
+	pid       PID
+	tag       string
+	restarts  int
+	restarted bool
+	state     processorState
+	init      sync.Once
+
+	watchMu    sync.Mutex
+	watchers   map[string]PID
+	terminated bool
+
+	idleTimer    Timer
+	lastActivity time.Time
+	passivated   atomic.Bool
+	grain        bool
+
+	stopping    *stopping
+	done        chan struct{}
+	stoppedOnce sync.Once
+}
+
+// stopping is the state of an actor that is waiting on its children to stop.
+type stopping struct {
+	children []PID
+	release  []any
+	held     []Envelope
 }
 
 func newProcessor(engine *Engine, opts *Options) *processor {
-	pid := NewPID(engine.pid.Address, opts.Name, opts.Tags...)
+	if opts.Receiver == nil && opts.Producer != nil {
+		opts.Receiver = opts.Producer()
+	}
+
+	pid := NewPID(engine.address, opts.Name, opts.Tags...)
 	proc := &processor{
 		state:   processorStateCreated,
 		pid:     pid,
-		inbox:   NewInbox(opts.InboxSize),
+		tag:     pidTag(pid),
+		inbox:   newInbox(opts.InboxSize, opts.Dispatcher, opts.Throughput),
 		options: opts,
 		context: newContext(engine, pid),
+		done:    make(chan struct{}),
 	}
 
 	return proc
@@ -65,30 +95,78 @@ func (p *processor) Send(ctx context.Context, to PID, msg any, from PID) {
 	env.From = from
 	env.Message = msg
 	env.Context = ctx
+	env.seq = 0
 	if env.Context == nil {
 		env.Context = p.context.engine.options.Context
 	}
 
+	if err := p.journal(env); err != nil {
+		p.context.logger.Error("Failed to journal message.", "from", from, "msg", reflect.TypeOf(msg), "err", err)
+		envelopePool.Put(env)
+		if !p.pid.Equals(p.context.engine.deadletter) {
+			p.context.engine.deadLetter(ctx, to, msg, from)
+		}
+		return
+	}
+
+	// a journaled message that isn't delivered stays in the journal, it is replayed once the actor is started again
 	if err := p.inbox.Deliver(env); err != nil {
+		// the actor was passivated while this was sent, so send it again to get a new activation, which journals it again before it is taken out of ours
+		if p.passivated.Load() {
+			p.context.engine.send(ctx, to, msg, from)
+			p.processed(env)
+			envelopePool.Put(env)
+			return
+		}
+
+		// the actor won't run again, so there is nothing for a poison or stop to wait on
+		if errors.Is(err, ErrDispatcherStopped) && isSystemMessage(msg) {
+			envelopePool.Put(env)
+			release(msg)
+			return
+		}
+
+		if (errors.Is(err, ErrInboxFull) || errors.Is(err, ErrDispatcherStopped)) && !p.pid.Equals(p.context.engine.deadletter) {
+			envelopePool.Put(env)
+			p.context.engine.deadLetter(ctx, to, msg, from)
+			return
+		}
+
+		// the actor is already stopping, which is all a poison or stop is waiting on
+		if isSystemMessage(msg) {
+			envelopePool.Put(env)
+			go func() {
+				<-p.done
+				release(msg)
+			}()
+			return
+		}
+
 		p.context.logger.Error("Failed to deliver message to inbox.", "inbox", p.pid, "from", from, "msg", reflect.TypeOf(msg), "err", err)
+		envelopePool.Put(env)
 	}
 }
 
 func (p *processor) Process(env *Envelope) {
 	defer envelopePool.Put(env)
 
-	// uncomment this for low level message process logging, its super spammy if left on, even at trace level
-	// p.context.logger.Info("processor.Process", "backlog", len(p.inbox.box), "to", env.To, "from", env.From, "msg", reflect.TypeOf(env.Message), "content", env.Message)
+	p.options.Metrics.InboxDepth(p.pid, p.tag, p.inbox.len())
 
 	defer func() {
 		if v := recover(); v != nil {
+			p.options.Metrics.Panic(p.pid, p.tag)
+
 			// TODO: send this message to a poison processor with the error associated with it
 
-			// only send the stop if in a valid state to actually stop
+			// only send the stop if in a valid state to actually stop, an actor that will be restarted is told it is restarting first
 			if p.state == processorStateStarted {
 				p.context.ctx = p.context.engine.options.Context
+				if p.options.MaxRestarts > 0 && p.restarts < p.options.MaxRestarts {
+					p.context.message = Restarting{Reason: v, Message: env.Message}
+					p.applyMiddleware(p.context.receiver.Receive, p.options.Middleware...)(p.context)
+				}
 				p.context.message = Stopped{}
-				p.context.receiver.Receive(p.context)
+				p.applyMiddleware(p.context.receiver.Receive, p.options.Middleware...)(p.context)
 			}
 
 			p.state = processorStateStopped
@@ -99,6 +177,26 @@ func (p *processor) Process(env *Envelope) {
 		}
 	}()
 
+	switch msg := env.Message.(type) {
+	case poisonPill, stop:
+		// meant for an actor that is already gone, so it ended up on the deadletter
+		if !env.To.Equals(p.pid) {
+			release(msg)
+			return
+		}
+	}
+
+	if p.stopping != nil {
+		p.processStopping(env)
+		return
+	}
+
+	// stopping right away, an actor that hasn't started yet isn't started just to be stopped
+	if msg, ok := env.Message.(stop); ok {
+		p.cleanup(msg)
+		return
+	}
+
 	// TODO: Check to see if context.Deadline and drop if Options say we should
 	rcv := p.context.receiver
 
@@ -114,6 +212,12 @@ func (p *processor) Process(env *Envelope) {
 		p.context.message = Started{}
 		p.applyMiddleware(rcv.Receive, p.options.Middleware...)(p.context)
 		p.state = processorStateStarted
+
+		if p.restarted {
+			p.restarted = false
+			p.context.message = Restarted{Count: p.restarts}
+			p.applyMiddleware(rcv.Receive, p.options.Middleware...)(p.context)
+		}
 	}
 
 	// just kicking off
@@ -122,16 +226,28 @@ func (p *processor) Process(env *Envelope) {
 	}
 
 	if pill, ok := env.Message.(poisonPill); ok {
-		p.cleanup(pill.wg)
+		p.cleanup(pill)
 		return
 	}
 
+	if _, ok := env.Message.(passivate); ok {
+		p.passivate()
+		return
+	}
+
+	if p.options.IdleTimeout > 0 {
+		p.lastActivity = p.options.Clock.Now()
+	}
+
 	p.context.message = env.Message
 	p.context.sender = env.From
 	p.context.target = env.To
 	p.context.ctx = env.Context
 
+	start := time.Now()
 	p.applyMiddleware(rcv.Receive, p.options.Middleware...)(p.context)
+	p.options.Metrics.MessageProcessed(p.pid, p.tag, time.Since(start))
+	p.processed(env)
 }
 
 func (p *processor) Start() {
@@ -139,13 +255,33 @@ func (p *processor) Start() {
 
 	// kick off initialize of the actor if it hasn't already
 	p.init.Do(func() {
+		if p.options.IdleTimeout > 0 {
+			if p.options.Producer == nil {
+				p.context.logger.Warn("Actor has an idle timeout but no producer, it will not be passivated.", "idleTimeout", p.options.IdleTimeout)
+			} else {
+				p.lastActivity = p.options.Clock.Now()
+				p.armIdleTimer(p.options.IdleTimeout)
+			}
+		}
+
+		if p.options.RecreateOnRestart && p.options.Producer == nil {
+			p.context.logger.Warn("Actor is recreated on restart but has no producer, its receiver will be reused.")
+		}
+
 		p.inbox.Process(p)
 		p.Send(context.Background(), p.pid, initialize{}, p.pid)
 	})
 }
 
+// Shutdown stops the actor, wg is done once it has stopped.
 func (p *processor) Shutdown(wg *sync.WaitGroup) {
-	p.cleanup(wg)
+	done := p.context.engine.Stop(p.pid)
+	if wg != nil {
+		go func() {
+			<-done
+			wg.Done()
+		}()
+	}
 }
 
 func (p *processor) applyMiddleware(rcv ReceiverFunc, middleware ...Middleware) ReceiverFunc {
@@ -155,43 +291,202 @@ func (p *processor) applyMiddleware(rcv ReceiverFunc, middleware ...Middleware)
 	return rcv
 }
 
-func (p *processor) cleanup(wg *sync.WaitGroup) {
-	p.context.engine.registry.remove(p.pid)
-	p.inbox.Close()
+// cleanup stops the actor, once its children have been stopped, msg is released once it has.
+// Children are stopped one at a time on their own goroutines, the last one spawned first, while the actor waits for them to terminate.
+func (p *processor) cleanup(msg any) {
+	if p.stopping != nil {
+		p.stopping.release = append(p.stopping.release, msg)
+		return
+	}
 
-	if p.context.parentContext != nil {
-		p.context.parentContext.children.Delete(p.pid.ID)
+	p.stopping = &stopping{release: []any{msg}}
+
+	if p.idleTimer != nil {
+		p.idleTimer.Stop()
 	}
 
-	if p.context.children.Len() > 0 {
-		children := p.context.Children()
-		for _, pid := range children {
-			proc := p.context.engine.registry.get(pid)
-			if proc == nil {
-				continue
-			}
+	// a passivated actor is still addressable, so it keeps its place with its parent and its subscriptions
+	if !p.passivated.Load() {
+		p.context.engine.pubsub.unsubscribeAll(p.pid)
 
-			if wg != nil {
-				wg.Add(1)
-			}
+		if p.context.parentContext != nil {
+			p.context.parentContext.children.Delete(p.pid.ID)
+		}
+	}
+
+	children := p.context.Children()
+	for i := len(children) - 1; i >= 0; i-- {
+		p.stopping.children = append(p.stopping.children, children[i])
+	}
+
+	p.stopNextChild()
+}
+
+// stopNextChild stops the next child, or finishes stopping the actor when there are none left.
+func (p *processor) stopNextChild() {
+	if len(p.stopping.children) == 0 {
+		p.stopped()
+		return
+	}
 
-			proc.Shutdown(wg)
+	child := p.stopping.children[0]
+	p.context.engine.Watch(child, p.pid)
+	p.context.engine.Stop(child)
+}
+
+// processStopping handles the messages that arrive while waiting on the children to stop.
+func (p *processor) processStopping(env *Envelope) {
+	switch msg := env.Message.(type) {
+	case Terminated:
+		if len(p.stopping.children) > 0 && msg.PID.Equals(p.stopping.children[0]) {
+			p.stopping.children = p.stopping.children[1:]
+			p.stopNextChild()
+		}
+
+	case poisonPill, stop:
+		p.stopping.release = append(p.stopping.release, msg)
+
+	case initialize, passivate:
+
+	default:
+		// a passivated actor hands these to its next activation once it has stopped
+		if p.passivated.Load() {
+			p.stopping.held = append(p.stopping.held, *env)
+			return
+		}
+
+		// journaled messages are kept for when the actor is started again
+		if env.seq != 0 {
+			return
+		}
+
+		if !p.pid.Equals(p.context.engine.deadletter) {
+			p.context.engine.deadLetter(env.Context, env.To, env.Message, env.From)
 		}
 	}
+}
+
+// stopped finishes stopping the actor once all of its children have.
+func (p *processor) stopped() {
+	passivated := p.passivated.Load()
+	engine := p.context.engine
 
 	// only send the stop if in a valid state to actually stop
+	// the actor keeps its name until it has, so a passivated actor isn't activated again while it is still stopping, messages sent meanwhile wait in the inbox
 	if p.state == processorStateStarted {
-		p.context.ctx = p.context.engine.options.Context
+		p.context.ctx = engine.options.Context
 		p.context.message = Stopped{}
 		p.applyMiddleware(p.context.receiver.Receive, p.options.Middleware...)(p.context)
 	}
 
+	engine.registry.remove(p.pid)
+	p.inbox.Close()
+
 	p.state = processorStateStopped
 
-	// send events
-	if wg != nil {
-		wg.Done()
+	p.watchMu.Lock()
+	p.terminated = true
+	watchers := p.watchers
+	p.watchers = nil
+	p.watchMu.Unlock()
+
+	for _, watcher := range watchers {
+		engine.send(engine.options.Context, watcher, Terminated{PID: p.pid}, p.pid)
+	}
+
+	if passivated {
+		// anything that made it to us while we were shutting down goes to the next activation
+		for _, env := range p.stopping.held {
+			p.processed(&env)
+			engine.send(env.Context, env.To, env.Message, env.From)
+		}
+		for env, ok := p.inbox.next(); ok; env, ok = p.inbox.next() {
+			p.processed(env)
+			engine.send(env.Context, env.To, env.Message, env.From)
+			envelopePool.Put(env)
+		}
+	} else {
+		p.deadLetterRemaining()
+	}
+	p.options.Metrics.InboxDepth(p.pid, p.tag, 0)
+
+	p.stoppedOnce.Do(func() { close(p.done) })
+
+	for _, msg := range p.stopping.release {
+		release(msg)
+	}
+}
+
+// deadLetterRemaining empties the closed inbox, so messages sent before the actor stopped aren't silently lost.
+func (p *processor) deadLetterRemaining() {
+	for env, ok := p.inbox.next(); ok; env, ok = p.inbox.next() {
+		switch msg := env.Message.(type) {
+		case poisonPill, stop:
+			// the actor has stopped, which is all these were waiting on
+			release(msg)
+
+		case initialize, passivate:
+
+		default:
+			// journaled messages are kept for when the actor is started again
+			if env.seq == 0 && !p.pid.Equals(p.context.engine.deadletter) {
+				p.context.engine.deadLetter(env.Context, env.To, env.Message, env.From)
+			}
+		}
+
+		envelopePool.Put(env)
+	}
+}
+
+// forceStop stops an actor that didn't stop when asked to, its receiver may still be running, but it won't be given any more messages.
+func (p *processor) forceStop() {
+	p.context.engine.registry.remove(p.pid)
+	p.inbox.Close()
+	p.context.engine.pubsub.unsubscribeAll(p.pid)
+
+	p.watchMu.Lock()
+	p.terminated = true
+	watchers := p.watchers
+	p.watchers = nil
+	p.watchMu.Unlock()
+
+	for _, watcher := range watchers {
+		p.context.engine.send(p.context.engine.options.Context, watcher, Terminated{PID: p.pid}, p.pid)
+	}
+
+	p.deadLetterRemaining()
+	p.stoppedOnce.Do(func() { close(p.done) })
+}
+
+// depth of the actor in the hierarchy, root actors are 0.
+func (p *processor) depth() int {
+	depth := 0
+	for parent := p.context.parentContext; parent != nil; parent = parent.parentContext {
+		depth++
+	}
+	return depth
+}
+
+func (p *processor) addWatcher(watcher PID) bool {
+	p.watchMu.Lock()
+	defer p.watchMu.Unlock()
+
+	if p.terminated {
+		return false
+	}
+
+	if p.watchers == nil {
+		p.watchers = make(map[string]PID)
 	}
+	p.watchers[watcher.String()] = watcher
+
+	return true
+}
+
+func (p *processor) removeWatcher(watcher PID) {
+	p.watchMu.Lock()
+	defer p.watchMu.Unlock()
+	delete(p.watchers, watcher.String())
 }
 
 func (p *processor) tryRestart(v any) {
@@ -203,10 +498,21 @@ func (p *processor) tryRestart(v any) {
 		return
 	}
 
+	p.options.Metrics.Restart(p.pid, p.tag)
 	p.context.logger.Warn("Actor process restarting.", "restarts", p.restarts, "maxRestarts", p.options.MaxRestarts, "err", v)
-	time.Sleep(p.options.RestartDelay)
 
-	if len(p.inbox.box) == 0 {
-		p.Send(context.Background(), p.pid, initialize{}, p.pid)
+	// the receiver may have been left in a bad state by the panic
+	if p.options.RecreateOnRestart && p.options.Producer != nil {
+		p.context.receiver = p.options.Producer()
 	}
+	p.restarted = true
+
+	// hold on to any messages until the restart delay has passed
+	p.inbox.suspend()
+	p.options.Clock.AfterFunc(p.options.RestartDelay, func() {
+		if p.inbox.len() == 0 {
+			p.Send(context.Background(), p.pid, initialize{}, p.pid)
+		}
+		p.inbox.resume()
+	})
 }
@@ -0,0 +1,53 @@
+package actor
+
+import "strconv"
+
+// Props describes how to create an actor, the Producer creating its receiver and the options it is spawned with.
+// Every actor spawned from Props gets a new receiver, which is also used when it is restarted with WithRecreateOnRestart, or activated after being passivated.
+type Props struct {
+	Producer Producer
+	Options  []Option
+}
+
+// NewProps creates Props for actors with receivers created by the producer.
+func NewProps(producer Producer, opts ...Option) Props {
+	return Props{Producer: producer, Options: opts}
+}
+
+// With returns a copy of the Props with more options.
+func (p Props) With(opts ...Option) Props {
+	return Props{Producer: p.Producer, Options: p.options(opts)}
+}
+
+// options the actor is spawned with, opts are applied last.
+func (p Props) options(opts []Option) []Option {
+	options := make([]Option, 0, len(p.Options)+len(opts)+1)
+	options = append(options, WithProducer(p.Producer))
+	options = append(options, p.Options...)
+	return append(options, opts...)
+}
+
+// SpawnProps spawns an actor with a new receiver from the Props.
+func (e *Engine) SpawnProps(props Props, name string, opts ...Option) PID {
+	return e.Spawn(nil, name, props.options(opts)...)
+}
+
+// TrySpawnProps spawns an actor with a new receiver from the Props, see TrySpawn.
+func (e *Engine) TrySpawnProps(props Props, name string, opts ...Option) (PID, error) {
+	return e.TrySpawn(nil, name, props.options(opts)...)
+}
+
+// SpawnPool spawns size actors from the Props, each with their own receiver, tagged with their index in the pool.
+// The PIDs can be used as the routees of a router.
+func (e *Engine) SpawnPool(props Props, name string, size int, opts ...Option) []PID {
+	pids := make([]PID, size)
+	for i := range pids {
+		pids[i] = e.SpawnProps(props, name, append(opts[:len(opts):len(opts)], WithTags(strconv.Itoa(i)))...)
+	}
+	return pids
+}
+
+// SpawnProps spawns a child actor with a new receiver from the Props.
+func (c *Context) SpawnProps(props Props, name string, opts ...Option) PID {
+	return c.Spawn(nil, name, props.options(opts)...)
+}
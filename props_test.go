@@ -0,0 +1,74 @@
+package actor_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/renevo/actor"
+)
+
+type instanceReceiver struct {
+	instance int
+}
+
+func (r *instanceReceiver) Receive(ctx *actor.Context) {
+	if _, ok := ctx.Message().(string); ok {
+		ctx.Respond(r.instance)
+	}
+}
+
+func TestSpawnProps(t *testing.T) {
+	is := is.New(t)
+
+	engine := actor.NewEngine()
+	defer engine.ShutdownAndWait()
+
+	instances := 0
+	props := actor.NewProps(func() actor.Receiver {
+		instances++
+		return &instanceReceiver{instance: instances}
+	}, actor.WithTags("props"))
+
+	a := engine.SpawnProps(props, "a")
+	b := engine.SpawnProps(props.With(actor.WithTags("extra")), "b")
+	is.Equal("a.props", a.ID)
+	is.Equal("b.props.extra", b.ID)
+
+	// every actor gets its own receiver
+	resp, err := engine.Request(a, "instance", time.Second)
+	is.NoErr(err)
+	is.Equal(1, resp)
+
+	resp, err = engine.Request(b, "instance", time.Second)
+	is.NoErr(err)
+	is.Equal(2, resp)
+}
+
+func TestSpawnPool(t *testing.T) {
+	is := is.New(t)
+
+	engine := actor.NewEngine()
+	defer engine.ShutdownAndWait()
+
+	instances := 0
+	props := actor.NewProps(func() actor.Receiver {
+		instances++
+		return &instanceReceiver{instance: instances}
+	})
+
+	pids := engine.SpawnPool(props, "worker", 3)
+	is.Equal([]actor.PID{
+		actor.NewPID(actor.LocalAddress, "worker", "0"),
+		actor.NewPID(actor.LocalAddress, "worker", "1"),
+		actor.NewPID(actor.LocalAddress, "worker", "2"),
+	}, pids)
+
+	seen := map[any]bool{}
+	for _, pid := range pids {
+		resp, err := engine.Request(pid, "instance", time.Second)
+		is.NoErr(err)
+		seen[resp] = true
+	}
+	is.Equal(3, len(seen)) // the workers don't share a receiver
+}
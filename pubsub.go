@@ -0,0 +1,155 @@
+package actor
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+const (
+	topicWildcard     = "*"
+	topicTailWildcard = ">"
+)
+
+type pubsub struct {
+	mu   sync.RWMutex
+	subs map[string]map[string]PID
+}
+
+func newPubSub() *pubsub {
+	return &pubsub{
+		subs: make(map[string]map[string]PID),
+	}
+}
+
+func (ps *pubsub) subscribe(topic string, pid PID) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	subs, ok := ps.subs[topic]
+	if !ok {
+		subs = make(map[string]PID)
+		ps.subs[topic] = subs
+	}
+
+	subs[pid.String()] = pid
+}
+
+func (ps *pubsub) unsubscribe(topic string, pid PID) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	subs, ok := ps.subs[topic]
+	if !ok {
+		return
+	}
+
+	delete(subs, pid.String())
+	if len(subs) == 0 {
+		delete(ps.subs, topic)
+	}
+}
+
+func (ps *pubsub) unsubscribeAll(pid PID) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	key := pid.String()
+	for topic, subs := range ps.subs {
+		delete(subs, key)
+		if len(subs) == 0 {
+			delete(ps.subs, topic)
+		}
+	}
+}
+
+func (ps *pubsub) subscribers(topic string) []PID {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	var pids []PID
+	seen := make(map[string]struct{})
+	for pattern, subs := range ps.subs {
+		if !matchTopic(pattern, topic) {
+			continue
+		}
+
+		for key, pid := range subs {
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			pids = append(pids, pid)
+		}
+	}
+
+	return pids
+}
+
+// matchTopic matches a topic against a pattern, segments are separated by "."
+// "*" will match exactly one segment, and ">" at the end of the pattern will match one or more remaining segments.
+func matchTopic(pattern, topic string) bool {
+	if pattern == topic {
+		return true
+	}
+
+	patterns := strings.Split(pattern, pidSeparator)
+	topics := strings.Split(topic, pidSeparator)
+
+	for i, p := range patterns {
+		if p == topicTailWildcard && i == len(patterns)-1 {
+			return len(topics) > i
+		}
+
+		if i >= len(topics) {
+			return false
+		}
+
+		if p != topicWildcard && p != topics[i] {
+			return false
+		}
+	}
+
+	return len(patterns) == len(topics)
+}
+
+// Subscribe the given PID to a topic. Topics are segments separated by ".", a subscription can use "*" to match any single segment, or ">" as the last segment to match all remaining segments.
+// Subscriptions are removed automatically when the subscribed actor stops.
+func (e *Engine) Subscribe(topic string, pid PID) {
+	e.pubsub.subscribe(topic, pid)
+}
+
+// Unsubscribe the given PID from a topic, the topic must match the one used to Subscribe.
+func (e *Engine) Unsubscribe(topic string, pid PID) {
+	e.pubsub.unsubscribe(topic, pid)
+}
+
+// Publish a message to all actors subscribed to a matching topic, nothing is published once the engine is shutting down with ShutdownContext.
+func (e *Engine) Publish(ctx context.Context, topic string, msg any) {
+	if e.stopping.Load() {
+		return
+	}
+
+	e.publish(ctx, topic, msg, e.pid)
+}
+
+func (e *Engine) publish(ctx context.Context, topic string, msg any, from PID) {
+	for _, pid := range e.pubsub.subscribers(topic) {
+		e.send(ctx, pid, msg, from)
+	}
+}
+
+// Subscribe this actor to a topic.
+func (c *Context) Subscribe(topic string) {
+	c.engine.Subscribe(topic, c.pid)
+}
+
+// Unsubscribe this actor from a topic.
+func (c *Context) Unsubscribe(topic string) {
+	c.engine.Unsubscribe(topic, c.pid)
+}
+
+// Publish a message to all actors subscribed to a matching topic with this actor as the sender.
+func (c *Context) Publish(ctx context.Context, topic string, msg any) {
+	c.engine.publish(ctx, topic, msg, c.pid)
+}
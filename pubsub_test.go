@@ -0,0 +1,106 @@
+package actor_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/renevo/actor"
+)
+
+func TestPublishSubscribe(t *testing.T) {
+	is := is.New(t)
+
+	engine := actor.NewEngine()
+	wg := &sync.WaitGroup{}
+	mu := sync.Mutex{}
+	received := make(map[string][]string)
+
+	subscriber := func(name string, topics ...string) actor.PID {
+		started := &sync.WaitGroup{}
+		started.Add(1)
+		pid := engine.SpawnFunc(func(ctx *actor.Context) {
+			switch msg := ctx.Message().(type) {
+			case actor.Started:
+				for _, topic := range topics {
+					ctx.Subscribe(topic)
+				}
+				started.Done()
+			case string:
+				mu.Lock()
+				received[name] = append(received[name], msg)
+				mu.Unlock()
+				wg.Done()
+			}
+		}, "TestPublishSubscribe", actor.WithTags(name))
+		started.Wait()
+		return pid
+	}
+
+	subscriber("exact", "orders.created")
+	subscriber("single", "orders.*")
+	subscriber("tail", "orders.>")
+	subscriber("overlap", "orders.created", "orders.*")
+
+	wg.Add(4)
+	engine.Publish(context.Background(), "orders.created", "a")
+	wg.Wait()
+
+	wg.Add(4)
+	engine.Publish(context.Background(), "orders.eu.created", "b")
+	engine.Publish(context.Background(), "orders.deleted", "c")
+	wg.Wait()
+
+	is.Equal([]string{"a"}, received["exact"])
+	is.Equal([]string{"a", "c"}, received["single"])
+	is.Equal([]string{"a", "b", "c"}, received["tail"])
+	is.Equal([]string{"a", "c"}, received["overlap"]) // one delivery even with multiple matching subscriptions
+
+	engine.ShutdownAndWait()
+}
+
+func TestUnsubscribeOnStop(t *testing.T) {
+	is := is.New(t)
+
+	engine := actor.NewEngine()
+	received := make(chan string, 10)
+
+	spawn := func(subscribe bool) actor.PID {
+		started := make(chan struct{})
+		pid := engine.SpawnFunc(func(ctx *actor.Context) {
+			switch msg := ctx.Message().(type) {
+			case actor.Started:
+				if subscribe {
+					ctx.Subscribe("events")
+				}
+				close(started)
+			case string:
+				received <- msg
+			}
+		}, "TestUnsubscribeOnStop")
+		<-started
+		return pid
+	}
+
+	pid := spawn(true)
+	engine.Publish(context.Background(), "events", "first")
+	is.Equal("first", <-received)
+
+	wg := &sync.WaitGroup{}
+	engine.Poison(pid, wg)
+	wg.Wait()
+
+	// same PID, but the subscription should be gone with the stopped actor
+	spawn(false)
+	engine.Publish(context.Background(), "events", "second")
+
+	select {
+	case msg := <-received:
+		t.Fatalf("unexpected message after stop: %s", msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	engine.ShutdownAndWait()
+}
@@ -1,10 +1,17 @@
 package actor
 
 import (
-	"reflect"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"sync"
 )
 
+var (
+	ErrDuplicateActor = errors.New("duplicate actor")
+)
+
 type registry struct {
 	mu     sync.RWMutex
 	lookup map[string]Processor
@@ -22,17 +29,17 @@ func (r *registry) get(pid PID) Processor {
 	return nil
 }
 
-func (r *registry) add(proc Processor) {
+func (r *registry) add(proc Processor) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	id := proc.PID().ID
-	if existing, ok := r.lookup[id]; ok {
-		r.engine.options.Logger.Warn("Attempt to register duplicate process.", "pid", proc.PID(), "existing", reflect.TypeOf(existing), "conflict", reflect.TypeOf(proc))
-		return
+	if _, ok := r.lookup[id]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateActor, proc.PID())
 	}
 
 	r.lookup[id] = proc
+	return nil
 }
 
 func (r *registry) remove(pid PID) {
@@ -46,8 +53,70 @@ func (r *registry) copy() map[PID]Processor {
 	defer r.mu.RUnlock()
 	c := make(map[PID]Processor, len(r.lookup))
 	for k, v := range r.lookup {
-		c[PID{Address: r.engine.pid.Address, ID: k}] = v
+		c[PID{Address: r.engine.address, ID: k}] = v
 	}
 
 	return c
 }
+
+// ids returns the ids of all registered actors that match.
+func (r *registry) ids(match func(id string, proc Processor) bool) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var ids []string
+	for id, proc := range r.lookup {
+		if match(id, proc) {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+// Find returns the PIDs of all actors matching the name and tags, sorted by ID.
+// Like topics, "*" matches any single name or tag, and ">" as the last tag matches all remaining tags, so Find("worker", "*") finds every worker.
+// Passivated actors are included, as they can still be sent messages, grains only while they are activated.
+func (e *Engine) Find(name string, tags ...string) []PID {
+	pattern := strings.Join(append([]string{name}, tags...), pidSeparator)
+	return e.pids(func(id string) bool { return matchTopic(pattern, id) })
+}
+
+// FindPrefix returns the PIDs of all actors with an ID under the given PID, such as its children and their children, sorted by ID.
+func (e *Engine) FindPrefix(pid PID) []PID {
+	prefix := pid.ID + pidSeparator
+	return e.pids(func(id string) bool { return strings.HasPrefix(id, prefix) })
+}
+
+// PIDs returns the PIDs of all actors on the engine, sorted by ID.
+// The engine's own actors, such as the deadletter actor and the ones waiting for a response to Request, are left out.
+func (e *Engine) PIDs() []PID {
+	return e.pids(func(string) bool { return true })
+}
+
+func (e *Engine) pids(match func(id string) bool) []PID {
+	ids := e.registry.ids(func(id string, proc Processor) bool {
+		if _, ok := proc.(*response); ok || id == e.pid.ID || id == e.deadletter.ID {
+			return false
+		}
+		return match(id)
+	})
+	e.passivated.ForEach(func(id string, entry *passivation) {
+		// grains that aren't activated only have an entry to hold their watchers
+		if entry.options != nil && match(id) {
+			ids = append(ids, id)
+		}
+	})
+	sort.Strings(ids)
+
+	pids := make([]PID, 0, len(ids))
+	for i, id := range ids {
+		// an actor can be activated while we are looking, and show up twice
+		if i > 0 && ids[i-1] == id {
+			continue
+		}
+		pids = append(pids, PID{Address: e.address, ID: id})
+	}
+
+	return pids
+}
@@ -0,0 +1,118 @@
+package reliable
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/renevo/actor"
+)
+
+const (
+	// maxWindows is how many outbox streams are remembered, the least recently used is forgotten first.
+	maxWindows = 1024
+	// maxAhead is how many messages are remembered past a gap, before giving up on what is missing.
+	maxAhead = 1024
+)
+
+// Consumer is the middleware for actors receiving messages from an Outbox.
+// Messages are unwrapped before they are received, acknowledged once they have been processed, and dropped when they have already been processed.
+//
+// A message isn't acknowledged when the receiver panics on it, so it is sent again once the actor has restarted.
+// What has been processed is kept by the middleware, so it isn't lost when the actor restarts or is passivated.
+// Only the most recently used streams are kept, and a stream with a large gap in it forgets about what is missing,
+// so a message sent again after a very long time may be processed twice.
+func Consumer() actor.Middleware {
+	d := &deduplicator{windows: make(map[string]*list.Element), lru: list.New()}
+
+	return func(next actor.ReceiverFunc) actor.ReceiverFunc {
+		return func(ctx *actor.Context) {
+			env, ok := ctx.Message().(Envelope)
+			if !ok {
+				next(ctx)
+				return
+			}
+
+			// the stream is the same wherever the message was forwarded to, so is what has been processed from it
+			key := env.Session + "/" + env.Stream
+			if !d.processed(key, env.Seq) {
+				next(ctx.WithMessage(env.Message))
+				d.mark(key, env.Seq)
+			}
+
+			to := env.Outbox
+			if to.IsZero() {
+				to = ctx.Sender()
+			}
+			ctx.Send(ctx.Context(), to, Ack{Session: env.Session, Stream: env.Stream, Seq: env.Seq})
+		}
+	}
+}
+
+type deduplicator struct {
+	mu      sync.Mutex
+	windows map[string]*list.Element
+	// least recently used window at the back
+	lru *list.List
+}
+
+// window is what has been processed from an outbox stream, everything up to upTo and anything after that in ahead.
+type window struct {
+	key   string
+	upTo  uint64
+	ahead map[uint64]bool
+}
+
+func (d *deduplicator) processed(key string, seq uint64) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	e, ok := d.windows[key]
+	if !ok {
+		return false
+	}
+	d.lru.MoveToFront(e)
+
+	w := e.Value.(*window)
+	return seq <= w.upTo || w.ahead[seq]
+}
+
+func (d *deduplicator) mark(key string, seq uint64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	e, ok := d.windows[key]
+	if ok {
+		d.lru.MoveToFront(e)
+	} else {
+		e = d.lru.PushFront(&window{key: key, ahead: make(map[uint64]bool)})
+		d.windows[key] = e
+
+		if d.lru.Len() > maxWindows {
+			oldest := d.lru.Back()
+			d.lru.Remove(oldest)
+			delete(d.windows, oldest.Value.(*window).key)
+		}
+	}
+
+	w := e.Value.(*window)
+	if seq <= w.upTo {
+		return
+	}
+
+	w.ahead[seq] = true
+
+	// too far ahead, skip over the oldest gap as whatever is missing from it is unlikely to arrive
+	for len(w.ahead) > maxAhead {
+		lowest := seq
+		for s := range w.ahead {
+			lowest = min(lowest, s)
+		}
+		delete(w.ahead, lowest)
+		w.upTo = lowest
+	}
+
+	for w.ahead[w.upTo+1] {
+		delete(w.ahead, w.upTo+1)
+		w.upTo++
+	}
+}
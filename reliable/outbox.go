@@ -0,0 +1,189 @@
+package reliable
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/renevo/actor"
+)
+
+const (
+	defaultRedeliverAfter    = time.Second
+	defaultMaxRedeliverAfter = time.Minute
+)
+
+type Config struct {
+	// RedeliverAfter is how long to wait for a message to be acknowledged before sending it again, defaults to 1 second.
+	RedeliverAfter time.Duration
+	// MaxRedeliverAfter caps how long to wait, which doubles every time a message is sent again, defaults to 1 minute.
+	MaxRedeliverAfter time.Duration
+	// MaxAttempts is how many times a message is sent before giving up on it and deadlettering it, it is sent until acknowledged when 0.
+	MaxAttempts int
+}
+
+// Outbox sends messages with at-least-once delivery.
+type Outbox struct {
+	engine *actor.Engine
+	pid    actor.PID
+}
+
+// NewOutbox spawns the actor that keeps track of the messages until they are acknowledged.
+func NewOutbox(engine *actor.Engine, name string, config Config, opts ...actor.Option) *Outbox {
+	if config.RedeliverAfter <= 0 {
+		config.RedeliverAfter = defaultRedeliverAfter
+	}
+	if config.MaxRedeliverAfter <= 0 {
+		config.MaxRedeliverAfter = max(defaultMaxRedeliverAfter, config.RedeliverAfter)
+	}
+
+	pid := engine.Spawn(&outbox{
+		config:  config,
+		session: newSession(),
+		streams: make(map[string]*stream),
+	}, name, opts...)
+
+	return &Outbox{engine: engine, pid: pid}
+}
+
+// PID of the outbox actor, acknowledgements are sent to it.
+func (o *Outbox) PID() actor.PID {
+	return o.pid
+}
+
+// Send a message to the given PID, it is sent again until the receiver acknowledges it.
+// The receiver has to use the Consumer middleware.
+func (o *Outbox) Send(ctx context.Context, to actor.PID, msg any) {
+	o.engine.Send(ctx, o.pid, send{to: to, msg: msg})
+}
+
+// Pending returns the number of messages that have not been acknowledged yet.
+func (o *Outbox) Pending() (int, error) {
+	resp, err := o.engine.Request(o.pid, pendingQuery{}, time.Second)
+	if err != nil {
+		return 0, err
+	}
+	return resp.(int), nil
+}
+
+type send struct {
+	to  actor.PID
+	msg any
+}
+
+type pendingQuery struct{}
+
+type redeliver struct{}
+
+type pending struct {
+	ctx      context.Context
+	msg      any
+	attempts int
+	// next is when it is sent again
+	next time.Time
+}
+
+// stream is everything sent to a single receiver, acknowledgements are matched to it by id rather than by who sent them, as the message may have been forwarded.
+type stream struct {
+	id      string
+	to      actor.PID
+	seq     uint64
+	pending map[uint64]*pending
+}
+
+type outbox struct {
+	config    Config
+	session   string
+	streams   map[string]*stream
+	repeater  actor.Repeater
+	repeating bool
+}
+
+func (o *outbox) Receive(ctx *actor.Context) {
+	switch msg := ctx.Message().(type) {
+	case actor.Started:
+		// restarts keep the receiver, which is still repeating
+		if !o.repeating {
+			o.repeater = ctx.SendRepeat(ctx.PID(), redeliver{}, o.config.RedeliverAfter)
+			o.repeating = true
+		}
+
+	case actor.Stopped:
+		if o.repeating {
+			o.repeater.Stop()
+			o.repeating = false
+		}
+
+	case send:
+		id := msg.to.String()
+		s, ok := o.streams[id]
+		if !ok {
+			s = &stream{id: id, to: msg.to, pending: make(map[uint64]*pending)}
+			o.streams[id] = s
+		}
+
+		s.seq++
+		s.pending[s.seq] = &pending{ctx: ctx.Context(), msg: msg.msg, attempts: 1, next: ctx.Engine().Clock().Now().Add(o.config.RedeliverAfter)}
+		o.deliver(ctx, ctx.Context(), s, s.seq, msg.msg)
+
+	case Ack:
+		if s, ok := o.streams[msg.Stream]; ok && msg.Session == o.session {
+			delete(s.pending, msg.Seq)
+		}
+
+	case redeliver:
+		o.redeliver(ctx)
+
+	case pendingQuery:
+		n := 0
+		for _, s := range o.streams {
+			n += len(s.pending)
+		}
+		ctx.Respond(n)
+	}
+}
+
+func (o *outbox) deliver(ctx *actor.Context, msgCtx context.Context, s *stream, seq uint64, msg any) {
+	ctx.Send(msgCtx, s.to, Envelope{Session: o.session, Stream: s.id, Seq: seq, Outbox: ctx.PID(), Message: msg})
+}
+
+// redeliver sends everything that hasn't been acknowledged in time again, in the order it was sent.
+// Messages that have been sent MaxAttempts times are given up on and deadlettered.
+func (o *outbox) redeliver(ctx *actor.Context) {
+	now := ctx.Engine().Clock().Now()
+
+	for _, s := range o.streams {
+		seqs := make([]uint64, 0, len(s.pending))
+		for seq, p := range s.pending {
+			if !now.Before(p.next) {
+				seqs = append(seqs, seq)
+			}
+		}
+		sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+
+		for _, seq := range seqs {
+			p := s.pending[seq]
+
+			if o.config.MaxAttempts > 0 && p.attempts >= o.config.MaxAttempts {
+				ctx.Log().Warn("Giving up on message that was never acknowledged.", "to", s.to, "seq", seq, "attempts", p.attempts)
+				delete(s.pending, seq)
+				ctx.Engine().DeadLetter(p.ctx, s.to, p.msg, ctx.PID())
+				continue
+			}
+
+			p.next = now.Add(o.backoff(p.attempts))
+			p.attempts++
+			ctx.Log().Debug("Redelivering message.", "to", s.to, "seq", seq, "attempt", p.attempts)
+			o.deliver(ctx, p.ctx, s, seq, p.msg)
+		}
+	}
+}
+
+// backoff is how long to wait for a message that has been sent the given number of times, doubling every time up to MaxRedeliverAfter.
+func (o *outbox) backoff(attempts int) time.Duration {
+	d := o.config.RedeliverAfter
+	for i := 0; i < attempts && d < o.config.MaxRedeliverAfter; i++ {
+		d *= 2
+	}
+	return min(d, o.config.MaxRedeliverAfter)
+}
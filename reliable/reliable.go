@@ -0,0 +1,46 @@
+// Package reliable provides opt-in at-least-once delivery between actors, on the same or on other engines.
+//
+// Messages are sent through an Outbox, which numbers them and keeps them until they are acknowledged, sending them again when they aren't acknowledged in time.
+// The receiving actor uses the Consumer middleware, which acknowledges messages once they have been processed and drops the ones it has already processed,
+// so a message that was lost on the way, or that the receiver panicked on, is processed once the receiver has restarted or the connection is back.
+//
+// Messages sent to other engines still need to be registered with remote.Register.
+package reliable
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/renevo/actor"
+	"github.com/renevo/actor/remote"
+)
+
+func init() {
+	remote.Register(Envelope{})
+	remote.Register(Ack{})
+}
+
+// Envelope carries a message sent through an Outbox.
+type Envelope struct {
+	// Session of the outbox that sent it, sequence numbers start over in a new session.
+	Session string
+	// Stream is the destination the message was sent to, each has its own sequence numbers.
+	Stream string
+	Seq    uint64
+	// Outbox the message is acknowledged to, which isn't always the sender once the message has been forwarded.
+	Outbox  actor.PID
+	Message any
+}
+
+// Ack acknowledges that a message has been processed, it is sent back to the outbox by the Consumer middleware.
+type Ack struct {
+	Session string
+	Stream  string
+	Seq     uint64
+}
+
+func newSession() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
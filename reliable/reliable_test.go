@@ -0,0 +1,192 @@
+package reliable_test
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/renevo/actor"
+	"github.com/renevo/actor/actortest"
+	"github.com/renevo/actor/reliable"
+	"github.com/renevo/actor/remote"
+)
+
+// eventually waits for the outbox to have nothing pending.
+func eventually(t *testing.T, outbox *reliable.Outbox) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		n, err := outbox.Pending()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d messages still pending", n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestRedeliveryAfterRestart(t *testing.T) {
+	is := is.New(t)
+
+	engine := actor.NewEngine()
+	t.Cleanup(engine.ShutdownAndWait)
+
+	var mu sync.Mutex
+	var received []string
+	failed := false
+
+	consumer := engine.SpawnFunc(func(ctx *actor.Context) {
+		msg, ok := ctx.Message().(string)
+		if !ok {
+			return
+		}
+
+		// lose the first message by panicking on it
+		if msg == "b" && !failed {
+			failed = true
+			panic("failed processing b")
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, msg)
+	}, "consumer", actor.WithMiddleware(reliable.Consumer()), actor.WithRestartDelay(time.Millisecond))
+
+	outbox := reliable.NewOutbox(engine, "outbox", reliable.Config{RedeliverAfter: 20 * time.Millisecond})
+	for _, msg := range []string{"a", "b", "c"} {
+		outbox.Send(context.Background(), consumer, msg)
+	}
+
+	eventually(t, outbox)
+
+	mu.Lock()
+	defer mu.Unlock()
+	is.Equal([]string{"a", "c", "b"}, received) // b was processed once the consumer restarted
+}
+
+func TestConsumerDeduplicates(t *testing.T) {
+	engine := actor.NewEngine()
+	t.Cleanup(engine.ShutdownAndWait)
+
+	probe := actortest.NewProbe(t, engine)
+	consumer := actortest.Spawn(t, engine, actor.ReceiverFunc(func(ctx *actor.Context) {
+		if msg, ok := ctx.Message().(string); ok {
+			ctx.Send(ctx.Context(), probe.PID(), "processed "+msg)
+		}
+	}), "consumer", actor.WithMiddleware(reliable.Consumer()))
+
+	for _, seq := range []uint64{1, 1, 3, 2, 3} {
+		engine.SendWithSender(context.Background(), consumer, reliable.Envelope{Session: "s", Seq: seq, Message: strconv.FormatUint(seq, 10)}, probe.PID())
+	}
+
+	// every delivery is acknowledged, but each message is only processed once
+	probe.ExpectMsg("processed 1")
+	probe.ExpectMsg(reliable.Ack{Session: "s", Seq: 1})
+	probe.ExpectMsg(reliable.Ack{Session: "s", Seq: 1})
+	probe.ExpectMsg("processed 3")
+	probe.ExpectMsg(reliable.Ack{Session: "s", Seq: 3})
+	probe.ExpectMsg("processed 2")
+	probe.ExpectMsg(reliable.Ack{Session: "s", Seq: 2})
+	probe.ExpectMsg(reliable.Ack{Session: "s", Seq: 3})
+	probe.ExpectNoMsg(10 * time.Millisecond)
+}
+
+func TestRedeliveryOverRemote(t *testing.T) {
+	r, err := remote.New(remote.Config{ListenAddr: "127.0.0.1:0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := actor.NewEngine(actor.WithRemote(r))
+	t.Cleanup(a.ShutdownAndWait)
+
+	outbox := reliable.NewOutbox(a, "outbox", reliable.Config{RedeliverAfter: 20 * time.Millisecond})
+
+	// the receiving engine isn't up yet, so the first attempts are dropped
+	listener, err := remote.New(remote.Config{ListenAddr: "127.0.0.1:0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Address()
+	listener.Stop()
+
+	outbox.Send(context.Background(), actor.NewPID(address, "consumer"), "hello")
+	time.Sleep(50 * time.Millisecond)
+
+	b, err := remote.New(remote.Config{ListenAddr: address})
+	if err != nil {
+		t.Skipf("unable to listen on %s again: %s", address, err)
+	}
+	engine := actor.NewEngine(actor.WithRemote(b))
+	t.Cleanup(engine.ShutdownAndWait)
+
+	received := make(chan string, 1)
+	engine.SpawnFunc(func(ctx *actor.Context) {
+		if msg, ok := ctx.Message().(string); ok {
+			received <- msg
+		}
+	}, "consumer", actor.WithMiddleware(reliable.Consumer()))
+
+	select {
+	case msg := <-received:
+		if msg != "hello" {
+			t.Fatalf("received %q", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("message was not redelivered")
+	}
+
+	eventually(t, outbox)
+}
+
+func TestAcknowledgedThroughRouter(t *testing.T) {
+	is := is.New(t)
+
+	engine := actor.NewEngine()
+	t.Cleanup(engine.ShutdownAndWait)
+
+	received := make(chan string, 1)
+	consumer := engine.SpawnFunc(func(ctx *actor.Context) {
+		if msg, ok := ctx.Message().(string); ok {
+			received <- msg
+		}
+	}, "consumer", actor.WithMiddleware(reliable.Consumer()))
+
+	// the routee acknowledges the message, not the router it was sent to
+	router := engine.Spawn(actor.NewConsistentHashRouter(func(any) (string, bool) { return "key", true }, consumer), "router")
+
+	outbox := reliable.NewOutbox(engine, "outbox", reliable.Config{RedeliverAfter: 20 * time.Millisecond})
+	outbox.Send(context.Background(), router, "hello")
+
+	is.Equal("hello", <-received)
+	eventually(t, outbox)
+}
+
+func TestMaxAttempts(t *testing.T) {
+	engine := actor.NewEngine()
+	t.Cleanup(engine.ShutdownAndWait)
+
+	// never acknowledges anything
+	probe := actortest.NewProbe(t, engine)
+
+	outbox := reliable.NewOutbox(engine, "outbox", reliable.Config{RedeliverAfter: 10 * time.Millisecond, MaxAttempts: 3})
+	outbox.Send(context.Background(), probe.PID(), "hello")
+
+	for i := 0; i < 3; i++ {
+		env := actortest.ExpectMsgType[reliable.Envelope](probe)
+		if env.Seq != 1 || env.Message != "hello" {
+			t.Fatalf("received %+v", env)
+		}
+	}
+
+	eventually(t, outbox)
+	probe.ExpectNoMsg(50 * time.Millisecond)
+}
@@ -0,0 +1,20 @@
+package actor
+
+import "context"
+
+// Remote delivers messages to actors that live on other engines.
+// Messages sent to a PID with an address other than the engine address (or LocalAddress) are handed to the Remote.
+type Remote interface {
+	// Address other engines can reach this engine on, this becomes the address of all actors on the engine.
+	Address() string
+	// Start delivering messages received from other engines to the given engine.
+	Start(e *Engine)
+	// Send a message to an actor on another engine.
+	Send(ctx context.Context, to PID, msg any, from PID)
+	// Stop the remote, this is called once the engine has shut down.
+	Stop()
+}
+
+func (e *Engine) isRemote(pid PID) bool {
+	return e.options.Remote != nil && pid.Address != e.address && pid.Address != LocalAddress
+}
@@ -0,0 +1,109 @@
+package remote
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+
+	"github.com/renevo/actor"
+)
+
+var (
+	ErrUnauthorized = errors.New("unauthorized")
+)
+
+// Delivery is a message received from another engine, that is yet to be delivered.
+type Delivery struct {
+	// From is the sender as claimed by the other engine, use TLS to check who the other engine is.
+	From    actor.PID
+	To      actor.PID
+	Message any
+	// RemoteAddr the connection was made from.
+	RemoteAddr net.Addr
+	// TLS is the state of the connection, it is nil when TLS isn't used.
+	TLS *tls.ConnectionState
+}
+
+// Authorizer decides if a message received from another engine is delivered, a message is dropped when an error is returned.
+type Authorizer interface {
+	Authorize(d Delivery) error
+}
+
+// AuthorizerFunc is a function that implements Authorizer.
+type AuthorizerFunc func(d Delivery) error
+
+func (f AuthorizerFunc) Authorize(d Delivery) error {
+	return f(d)
+}
+
+// Authorizers combines authorizers, a message is only delivered when all of them authorize it.
+func Authorizers(authorizers ...Authorizer) Authorizer {
+	return AuthorizerFunc(func(d Delivery) error {
+		for _, a := range authorizers {
+			if err := a.Authorize(d); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DenySenders denies messages from actors on the engines with the given addresses.
+// The address is the one the other engine claims to be sending from, which any engine can forge, so it keeps well behaved engines apart and can't be used for security; use AllowPeers for that.
+func DenySenders(addresses ...string) Authorizer {
+	denied := make(map[string]bool, len(addresses))
+	for _, address := range addresses {
+		denied[address] = true
+	}
+
+	return AuthorizerFunc(func(d Delivery) error {
+		if denied[d.From.Address] {
+			return fmt.Errorf("%w: sender %s", ErrUnauthorized, d.From.Address)
+		}
+		return nil
+	})
+}
+
+// AllowPeers only allows messages from engines that presented a verified certificate for one of the given names, its common name or any of its DNS names.
+// The engine must require and verify client certificates (tls.RequireAndVerifyClientCert), messages received without a verified certificate are denied.
+func AllowPeers(names ...string) Authorizer {
+	allowed := make(map[string]bool, len(names))
+	for _, name := range names {
+		allowed[name] = true
+	}
+
+	return AuthorizerFunc(func(d Delivery) error {
+		if d.TLS == nil || len(d.TLS.VerifiedChains) == 0 || len(d.TLS.VerifiedChains[0]) == 0 {
+			return fmt.Errorf("%w: no verified certificate from %s", ErrUnauthorized, d.RemoteAddr)
+		}
+
+		cert := d.TLS.VerifiedChains[0][0]
+		if allowed[cert.Subject.CommonName] {
+			return nil
+		}
+		for _, name := range cert.DNSNames {
+			if allowed[name] {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("%w: peer %s", ErrUnauthorized, cert.Subject.CommonName)
+	})
+}
+
+// DenyMessages denies messages with the same type as any of the given messages.
+func DenyMessages(messages ...any) Authorizer {
+	denied := make(map[reflect.Type]bool, len(messages))
+	for _, msg := range messages {
+		denied[reflect.TypeOf(msg)] = true
+	}
+
+	return AuthorizerFunc(func(d Delivery) error {
+		if t := reflect.TypeOf(d.Message); denied[t] {
+			return fmt.Errorf("%w: message %s", ErrUnauthorized, t)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,26 @@
+package remote
+
+import (
+	"encoding/gob"
+
+	"github.com/renevo/actor"
+)
+
+func init() {
+	Register(actor.PID{})
+	Register(actor.Terminated{})
+	Register(actor.SpawnRequest{})
+	Register(actor.SpawnResponse{})
+}
+
+// Register a message type so it can be sent to other engines, both sides need to register the same types.
+func Register(v any) {
+	gob.Register(v)
+}
+
+type envelope struct {
+	To      actor.PID
+	From    actor.PID
+	Message any
+	Header  map[string]string
+}
@@ -0,0 +1,237 @@
+package remote
+
+import (
+	"bufio"
+	"compress/flate"
+	"crypto/tls"
+	"encoding/gob"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	redialDelay = 500 * time.Millisecond
+)
+
+// the first byte written on a connection says how the rest of it is encoded.
+const (
+	encodingPlain byte = iota
+	encodingFlate
+)
+
+var (
+	errLinkFull = errors.New("send buffer full")
+	errLinkDown = errors.New("engine unreachable")
+)
+
+// link is an outbound connection to another engine, messages are queued and written in order by a single goroutine.
+// Messages are written in batches, which are flushed once BatchSize messages have been written, or FlushInterval has passed.
+type link struct {
+	remote   *Remote
+	address  string
+	queue    chan *envelope
+	done     chan struct{}
+	once     sync.Once
+	counters *counters
+}
+
+func newLink(r *Remote, address string) *link {
+	l := &link{
+		remote:   r,
+		address:  address,
+		queue:    make(chan *envelope, r.config.BufferSize),
+		done:     make(chan struct{}),
+		counters: &counters{},
+	}
+
+	r.wg.Add(1)
+	go l.run()
+
+	return l
+}
+
+func (l *link) send(env *envelope) {
+	select {
+	case <-l.done:
+		l.drop(env, errLinkDown)
+	case l.queue <- env:
+	default:
+		l.drop(env, errLinkFull)
+	}
+}
+
+func (l *link) drop(env *envelope, err error) {
+	l.counters.dropped.Add(1)
+	l.remote.logSendFailure(env, err)
+}
+
+func (l *link) close() {
+	l.once.Do(func() {
+		close(l.done)
+	})
+}
+
+func (l *link) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: l.remote.config.DialTimeout}
+	if l.remote.config.TLS != nil {
+		return tls.DialWithDialer(dialer, "tcp", l.address, l.remote.config.TLS)
+	}
+
+	return dialer.Dial("tcp", l.address)
+}
+
+// stream is an open connection messages are encoded on.
+type stream struct {
+	conn net.Conn
+	w    *bufio.Writer
+	fw   *flate.Writer
+	enc  *gob.Encoder
+	// messages written since the last flush
+	pending []*envelope
+}
+
+func (l *link) connect() (*stream, error) {
+	conn, err := l.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &stream{conn: conn, w: bufio.NewWriter(countingConn{Conn: conn, counters: l.counters})}
+
+	var w io.Writer = s.w
+	if l.remote.config.Compression {
+		_ = s.w.WriteByte(encodingFlate)
+		s.fw, _ = flate.NewWriter(s.w, flate.DefaultCompression)
+		w = s.fw
+	} else {
+		_ = s.w.WriteByte(encodingPlain)
+	}
+	s.enc = gob.NewEncoder(w)
+
+	return s, nil
+}
+
+func (s *stream) flush() error {
+	if s.fw != nil {
+		if err := s.fw.Flush(); err != nil {
+			return err
+		}
+	}
+	return s.w.Flush()
+}
+
+func (l *link) run() {
+	defer l.remote.wg.Done()
+
+	var s *stream
+	var redialAt time.Time
+
+	// set while a batch is waiting on the flush interval
+	var flushAt <-chan time.Time
+
+	disconnect := func(err error) {
+		if s == nil {
+			return
+		}
+
+		// nothing written since the last flush is known to have made it
+		for _, env := range s.pending {
+			l.drop(env, err)
+		}
+		_ = s.conn.Close()
+		s = nil
+	}
+
+	flush := func() {
+		flushAt = nil
+
+		if s == nil {
+			return
+		}
+
+		if err := s.flush(); err != nil {
+			disconnect(err)
+			return
+		}
+
+		if len(s.pending) == 0 {
+			return
+		}
+
+		l.counters.messages.Add(uint64(len(s.pending)))
+		l.counters.batches.Add(1)
+		s.pending = s.pending[:0]
+	}
+
+	defer func() {
+		flush()
+		disconnect(errLinkDown)
+
+		// nothing will send what is left in the queue
+		for {
+			select {
+			case env := <-l.queue:
+				l.drop(env, errLinkDown)
+			default:
+				return
+			}
+		}
+	}()
+
+	for {
+		var env *envelope
+		select {
+		case <-l.done:
+			return
+
+		case <-flushAt:
+			flush()
+			continue
+
+		case env = <-l.queue:
+		}
+
+		if s == nil {
+			// don't keep dialing an engine that just failed, everything sent in the meantime is dropped
+			if time.Now().Before(redialAt) {
+				l.drop(env, errLinkDown)
+				continue
+			}
+
+			var err error
+			s, err = l.connect()
+			if err != nil {
+				redialAt = time.Now().Add(redialDelay)
+				l.drop(env, err)
+				continue
+			}
+		}
+
+		if err := s.enc.Encode(env); err != nil {
+			l.drop(env, err)
+
+			// a message that can't be encoded isn't written, so only it is dropped,
+			// unless writing to the connection failed, which flushing finds out and disconnects as gob streams can't recover from it
+			flush()
+			continue
+		}
+		s.pending = append(s.pending, env)
+
+		switch {
+		case len(s.pending) >= l.remote.config.BatchSize:
+			flush()
+
+		case l.remote.config.FlushInterval <= 0:
+			// keep batching while there are more messages waiting
+			if len(l.queue) == 0 {
+				flush()
+			}
+
+		case flushAt == nil:
+			flushAt = time.After(l.remote.config.FlushInterval)
+		}
+	}
+}
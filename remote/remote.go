@@ -0,0 +1,292 @@
+// Package remote is a minimal TCP transport that lets actors send messages to actors on other engines, it is what the cluster package runs on.
+//
+// Messages are encoded with encoding/gob, so every message type sent between engines has to be registered with Register on both sides.
+// Delivery is fire-and-forget, the same as sending to a local actor.
+// Messages to each engine are written in batches over a single connection, optionally compressed, and Stats reports the throughput of every connection.
+//
+// Without TLS, connections are neither encrypted nor authenticated, and anything that can reach the listener can send messages to any actor on the engine
+// (and spawn actors of any registered kind with Engine.SpawnRemote), so it must only be used between engines on a trusted network (or over loopback).
+// Config.TLS encrypts connections, and authenticates engines to each other when it requires client certificates,
+// an Authorizer can then decide which messages are delivered.
+package remote
+
+import (
+	"bufio"
+	"compress/flate"
+	"context"
+	"crypto/tls"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/renevo/actor"
+)
+
+const (
+	defaultDialTimeout = 5 * time.Second
+	defaultBufferSize  = 1024
+	defaultBatchSize   = 128
+)
+
+// Propagator carries values of a context.Context between engines, such as tracing.TraceContext.
+type Propagator interface {
+	Inject(ctx context.Context, carrier map[string]string)
+	Extract(ctx context.Context, carrier map[string]string) context.Context
+}
+
+type Config struct {
+	// ListenAddr is the address to listen on, use "127.0.0.1:0" to pick a free port.
+	ListenAddr string
+	// DialTimeout for connecting to other engines.
+	DialTimeout time.Duration
+	// BufferSize is the number of messages that are queued for each engine before messages are dropped.
+	BufferSize int
+	// BatchSize is the most messages written to another engine before they are flushed, defaults to 128.
+	BatchSize int
+	// FlushInterval is how long messages are held on to, waiting for more to be sent in the same batch.
+	// When it is 0 messages are flushed as soon as there are no more waiting to be sent.
+	FlushInterval time.Duration
+	// Compression compresses messages sent to other engines with flate, engines receive compressed messages whether or not they compress their own.
+	Compression bool
+	// Propagator is used to carry context values with every message.
+	Propagator Propagator
+	// TLS is used for both accepting and making connections when it is set.
+	// For mutual authentication set ClientAuth to tls.RequireAndVerifyClientCert with the ClientCAs, along with the RootCAs and a certificate every engine can present.
+	TLS *tls.Config
+	// Authorizer decides which messages received from other engines are delivered, all of them are when it is nil.
+	Authorizer Authorizer
+	Logger     *slog.Logger
+}
+
+// Remote is an actor.Remote that sends messages over TCP.
+type Remote struct {
+	config   Config
+	listener net.Listener
+	engine   *actor.Engine
+	logger   *slog.Logger
+
+	mu     sync.Mutex
+	links  map[string]*link
+	conns  map[net.Conn]*counters
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// New creates a Remote that is listening on the configured address, messages are not received until it is started by an engine.
+func New(config Config) (*Remote, error) {
+	if config.DialTimeout <= 0 {
+		config.DialTimeout = defaultDialTimeout
+	}
+	if config.BufferSize <= 0 {
+		config.BufferSize = defaultBufferSize
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaultBatchSize
+	}
+	if config.Logger == nil {
+		config.Logger = slog.Default()
+	}
+
+	listener, err := net.Listen("tcp", config.ListenAddr)
+	if err != nil {
+		return nil, fmt.Errorf("unable to listen on %q: %w", config.ListenAddr, err)
+	}
+
+	if config.TLS != nil {
+		listener = tls.NewListener(listener, config.TLS)
+	}
+
+	return &Remote{
+		config:   config,
+		listener: listener,
+		logger:   config.Logger.With("remote", listener.Addr().String()),
+		links:    make(map[string]*link),
+		conns:    make(map[net.Conn]*counters),
+	}, nil
+}
+
+func (r *Remote) Address() string {
+	return r.listener.Addr().String()
+}
+
+func (r *Remote) Start(e *actor.Engine) {
+	r.engine = e
+
+	r.wg.Add(1)
+	go r.accept()
+}
+
+func (r *Remote) Send(ctx context.Context, to actor.PID, msg any, from actor.PID) {
+	l := r.link(to.Address)
+	if l == nil {
+		return
+	}
+
+	env := &envelope{To: to, From: from, Message: msg}
+	if r.config.Propagator != nil {
+		env.Header = make(map[string]string)
+		r.config.Propagator.Inject(ctx, env.Header)
+	}
+
+	l.send(env)
+}
+
+// Stop listening, and close all connections to other engines.
+func (r *Remote) Stop() {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return
+	}
+	r.closed = true
+
+	links := r.links
+	r.links = make(map[string]*link)
+	for conn := range r.conns {
+		_ = conn.Close()
+	}
+	r.mu.Unlock()
+
+	_ = r.listener.Close()
+	for _, l := range links {
+		l.close()
+	}
+
+	r.wg.Wait()
+}
+
+func (r *Remote) link(address string) *link {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return nil
+	}
+
+	l, ok := r.links[address]
+	if !ok {
+		l = newLink(r, address)
+		r.links[address] = l
+	}
+
+	return l
+}
+
+func (r *Remote) accept() {
+	defer r.wg.Done()
+
+	for {
+		conn, err := r.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				r.logger.Error("Failed to accept connection.", "err", err)
+			}
+			return
+		}
+
+		r.mu.Lock()
+		if r.closed {
+			r.mu.Unlock()
+			_ = conn.Close()
+			return
+		}
+		c := &counters{}
+		r.conns[conn] = c
+		r.mu.Unlock()
+
+		r.wg.Add(1)
+		go r.receive(conn, c)
+	}
+}
+
+func (r *Remote) receive(conn net.Conn, c *counters) {
+	defer func() {
+		r.mu.Lock()
+		delete(r.conns, conn)
+		r.mu.Unlock()
+
+		_ = conn.Close()
+		r.wg.Done()
+	}()
+
+	var state *tls.ConnectionState
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		// don't let a connection that never finishes the handshake hang around
+		_ = conn.SetDeadline(time.Now().Add(r.config.DialTimeout))
+		if err := tlsConn.Handshake(); err != nil {
+			r.logger.Warn("TLS handshake failed, closing connection.", "from", conn.RemoteAddr(), "err", err)
+			return
+		}
+		_ = conn.SetDeadline(time.Time{})
+
+		cs := tlsConn.ConnectionState()
+		state = &cs
+	}
+
+	br := bufio.NewReader(countingConn{Conn: conn, counters: c})
+	encoding, err := br.ReadByte()
+	if err != nil {
+		return
+	}
+
+	var rd io.Reader = br
+	switch encoding {
+	case encodingPlain:
+	case encodingFlate:
+		rd = flate.NewReader(br)
+	default:
+		r.logger.Error("Unknown connection encoding, closing connection.", "from", conn.RemoteAddr(), "encoding", encoding)
+		return
+	}
+
+	dec := gob.NewDecoder(rd)
+	for {
+		var env envelope
+		if err := dec.Decode(&env); err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				r.logger.Error("Failed to decode message, closing connection.", "from", conn.RemoteAddr(), "err", err)
+			}
+			return
+		}
+		c.messages.Add(1)
+
+		if r.config.Authorizer != nil {
+			err := r.config.Authorizer.Authorize(Delivery{
+				From:       env.From,
+				To:         env.To,
+				Message:    env.Message,
+				RemoteAddr: conn.RemoteAddr(),
+				TLS:        state,
+			})
+			if err != nil {
+				r.logger.Warn("Dropping unauthorized message.", "to", env.To, "from", env.From, "type", reflect.TypeOf(env.Message), "err", err)
+				continue
+			}
+		}
+
+		r.deliver(&env)
+	}
+}
+
+func (r *Remote) deliver(env *envelope) {
+	ctx := context.Background()
+	if r.config.Propagator != nil && len(env.Header) > 0 {
+		ctx = r.config.Propagator.Extract(ctx, env.Header)
+	}
+
+	// anything that made it here is for this engine
+	to := env.To
+	to.Address = r.engine.Address()
+
+	r.engine.Relay(ctx, to, env.Message, env.From)
+}
+
+func (r *Remote) logSendFailure(env *envelope, err error) {
+	r.logger.Warn("Failed to send message to remote engine.", "to", env.To, "from", env.From, "type", reflect.TypeOf(env.Message), "err", err)
+}
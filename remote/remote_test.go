@@ -0,0 +1,240 @@
+package remote_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/renevo/actor"
+	"github.com/renevo/actor/remote"
+	"github.com/renevo/actor/tracing"
+)
+
+type echo struct {
+	Text string
+}
+
+func init() {
+	remote.Register(echo{})
+}
+
+func newEngine(t *testing.T, config remote.Config) *actor.Engine {
+	t.Helper()
+
+	config.ListenAddr = "127.0.0.1:0"
+	r, err := remote.New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	engine := actor.NewEngine(actor.WithRemote(r))
+	t.Cleanup(engine.ShutdownAndWait)
+
+	return engine
+}
+
+func TestRemoteRequest(t *testing.T) {
+	is := is.New(t)
+
+	a := newEngine(t, remote.Config{})
+	b := newEngine(t, remote.Config{})
+	is.True(a.Address() != b.Address())
+
+	pid := b.SpawnFunc(func(ctx *actor.Context) {
+		if msg, ok := ctx.Message().(echo); ok {
+			ctx.Respond(echo{Text: "echo " + msg.Text + " from " + ctx.Sender().Address})
+		}
+	}, "echo")
+	is.Equal(b.Address(), pid.Address)
+
+	resp, err := a.Request(pid, echo{Text: "hello"}, time.Second)
+	is.NoErr(err)
+	is.Equal(echo{Text: "echo hello from " + a.Address()}, resp)
+}
+
+func TestRemoteTracePropagation(t *testing.T) {
+	is := is.New(t)
+
+	exporter := tracing.NewInMemoryExporter()
+	tracer := tracing.NewTracer(exporter)
+
+	a := newEngine(t, remote.Config{Propagator: tracing.TraceContext{}})
+	b := newEngine(t, remote.Config{Propagator: tracing.TraceContext{}})
+
+	// closes done once the traced message, and its span, has finished
+	done := make(chan struct{})
+	processed := func(next actor.ReceiverFunc) actor.ReceiverFunc {
+		return func(ctx *actor.Context) {
+			next(ctx)
+			if _, ok := ctx.Message().(echo); ok {
+				close(done)
+			}
+		}
+	}
+
+	pid := b.SpawnFunc(func(ctx *actor.Context) {}, "traced", actor.WithMiddleware(processed, tracing.Middleware(tracer)))
+
+	ctx, span := tracer.Start(context.Background(), "sender")
+	a.Send(ctx, pid, echo{Text: "hello"})
+	<-done
+
+	spans := exporter.Spans()
+	is.Equal(1, len(spans))
+	is.Equal(span.Context.TraceID, spans[0].Context.TraceID)
+	is.Equal(span.Context.SpanID, spans[0].Parent.SpanID)
+	is.True(spans[0].Parent.Remote)
+}
+
+func TestSpawnRemote(t *testing.T) {
+	is := is.New(t)
+
+	a := newEngine(t, remote.Config{})
+	b := newEngine(t, remote.Config{})
+
+	b.RegisterKind("echo", func() actor.Receiver {
+		return actor.ReceiverFunc(func(ctx *actor.Context) {
+			if msg, ok := ctx.Message().(echo); ok {
+				ctx.Respond(echo{Text: msg.Text + " from " + ctx.PID().Address})
+			}
+		})
+	})
+
+	pid, err := a.SpawnRemote(b.Address(), "echo", "spawned", actor.SpawnRemoteOptions{Tags: []string{"1"}})
+	is.NoErr(err)
+	is.Equal(actor.NewPID(b.Address(), "spawned", "1"), pid)
+
+	resp, err := a.Request(pid, echo{Text: "hello"}, time.Second)
+	is.NoErr(err)
+	is.Equal(echo{Text: "hello from " + b.Address()}, resp)
+
+	_, err = a.SpawnRemote(b.Address(), "echo", "spawned", actor.SpawnRemoteOptions{Tags: []string{"1"}})
+	is.True(errors.Is(err, actor.ErrDuplicateActor))
+
+	existing, err := a.SpawnRemote(b.Address(), "echo", "spawned", actor.SpawnRemoteOptions{Tags: []string{"1"}, SpawnPolicy: actor.SpawnPolicyExisting})
+	is.NoErr(err)
+	is.Equal(pid, existing)
+
+	_, err = a.SpawnRemote(b.Address(), "missing", "spawned", actor.SpawnRemoteOptions{})
+	is.True(errors.Is(err, actor.ErrUnknownKind))
+}
+
+// sendAll sends n messages from a to an actor on b, and waits for them to be received.
+func sendAll(t *testing.T, a, b *actor.Engine, n int, msg any) {
+	t.Helper()
+
+	received := make(chan struct{}, n)
+	pid := b.SpawnFunc(func(ctx *actor.Context) {
+		if _, ok := ctx.Message().(echo); ok {
+			received <- struct{}{}
+		}
+	}, "receiver")
+
+	for i := 0; i < n; i++ {
+		a.Send(context.Background(), pid, msg)
+	}
+
+	for i := 0; i < n; i++ {
+		select {
+		case <-received:
+		case <-time.After(time.Second):
+			t.Fatalf("received %d of %d messages", i, n)
+		}
+	}
+}
+
+// outbound returns the stats of the remote once it has counted n messages as sent, which can be just after they were received.
+func outbound(r *remote.Remote, n uint64) []remote.Stats {
+	deadline := time.Now().Add(time.Second)
+	for {
+		stats := r.Stats()
+		if len(stats) > 0 && stats[0].Messages >= n || time.Now().After(deadline) {
+			return stats
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestRemoteBatching(t *testing.T) {
+	is := is.New(t)
+
+	r, err := remote.New(remote.Config{ListenAddr: "127.0.0.1:0", BatchSize: 10, FlushInterval: 20 * time.Millisecond})
+	is.NoErr(err)
+	a := actor.NewEngine(actor.WithRemote(r))
+	t.Cleanup(a.ShutdownAndWait)
+	b := newEngine(t, remote.Config{})
+
+	sendAll(t, a, b, 25, echo{Text: "hello"})
+
+	stats := outbound(r, 25)
+	is.Equal(1, len(stats))
+	is.Equal(b.Address(), stats[0].Address)
+	is.Equal(uint64(25), stats[0].Messages)
+	is.Equal(uint64(3), stats[0].Batches) // two full batches, and the rest once the interval passed
+	is.True(stats[0].Bytes > 0)
+}
+
+func TestRemoteCompression(t *testing.T) {
+	is := is.New(t)
+
+	msg := echo{Text: strings.Repeat("compress me ", 100)}
+	bytes := map[bool]uint64{}
+
+	for _, compression := range []bool{false, true} {
+		r, err := remote.New(remote.Config{ListenAddr: "127.0.0.1:0", Compression: compression})
+		is.NoErr(err)
+		a := actor.NewEngine(actor.WithRemote(r))
+		t.Cleanup(a.ShutdownAndWait)
+		b := newEngine(t, remote.Config{})
+
+		sendAll(t, a, b, 50, msg)
+
+		stats := outbound(r, 50)
+		is.Equal(uint64(50), stats[0].Messages)
+		bytes[compression] = stats[0].Bytes
+	}
+
+	is.True(bytes[true] < bytes[false]/2) // repetitive messages compress well
+}
+
+// unregistered can't be encoded, as it isn't registered with gob.
+type unregistered struct {
+	Text string
+}
+
+func TestRemoteEncodeFailure(t *testing.T) {
+	is := is.New(t)
+
+	r, err := remote.New(remote.Config{ListenAddr: "127.0.0.1:0", FlushInterval: 20 * time.Millisecond})
+	is.NoErr(err)
+	a := actor.NewEngine(actor.WithRemote(r))
+	t.Cleanup(a.ShutdownAndWait)
+	b := newEngine(t, remote.Config{})
+
+	received := make(chan string, 3)
+	pid := b.SpawnFunc(func(ctx *actor.Context) {
+		if msg, ok := ctx.Message().(echo); ok {
+			received <- msg.Text
+		}
+	}, "receiver")
+
+	// all in the same batch, only the one that can't be encoded is dropped
+	a.Send(context.Background(), pid, echo{Text: "1"})
+	a.Send(context.Background(), pid, unregistered{Text: "2"})
+	a.Send(context.Background(), pid, echo{Text: "3"})
+
+	for _, want := range []string{"1", "3"} {
+		select {
+		case got := <-received:
+			is.Equal(want, got)
+		case <-time.After(time.Second):
+			t.Fatalf("message %s was not received", want)
+		}
+	}
+
+	stats := outbound(r, 2)
+	is.Equal(uint64(2), stats[0].Messages)
+	is.Equal(uint64(1), stats[0].Dropped)
+}
@@ -0,0 +1,81 @@
+package remote
+
+import (
+	"net"
+	"sort"
+	"sync/atomic"
+)
+
+// Stats are the throughput of a connection to or from another engine.
+type Stats struct {
+	// Address of the other engine for outbound connections, and the address the connection was made from for inbound connections.
+	Address string
+	Inbound bool
+	// Messages sent or received.
+	Messages uint64
+	// Bytes sent or received on the wire, after compression.
+	Bytes uint64
+	// Batches of messages written, outbound only.
+	Batches uint64
+	// Dropped messages that couldn't be sent, outbound only.
+	Dropped uint64
+}
+
+type counters struct {
+	messages atomic.Uint64
+	bytes    atomic.Uint64
+	batches  atomic.Uint64
+	dropped  atomic.Uint64
+}
+
+func (c *counters) stats(address string, inbound bool) Stats {
+	return Stats{
+		Address:  address,
+		Inbound:  inbound,
+		Messages: c.messages.Load(),
+		Bytes:    c.bytes.Load(),
+		Batches:  c.batches.Load(),
+		Dropped:  c.dropped.Load(),
+	}
+}
+
+// countingConn counts the bytes read from and written to a connection.
+type countingConn struct {
+	net.Conn
+	counters *counters
+}
+
+func (c countingConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	c.counters.bytes.Add(uint64(n))
+	return n, err
+}
+
+func (c countingConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	c.counters.bytes.Add(uint64(n))
+	return n, err
+}
+
+// Stats returns the throughput of the connections to other engines, and of the connections from other engines that are still open.
+func (r *Remote) Stats() []Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := make([]Stats, 0, len(r.links)+len(r.conns))
+	for address, l := range r.links {
+		stats = append(stats, l.counters.stats(address, false))
+	}
+	for conn, c := range r.conns {
+		stats = append(stats, c.stats(conn.RemoteAddr().String(), true))
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Inbound != stats[j].Inbound {
+			return !stats[i].Inbound
+		}
+		return stats[i].Address < stats[j].Address
+	})
+
+	return stats
+}
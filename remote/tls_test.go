@@ -0,0 +1,189 @@
+package remote_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/renevo/actor"
+	"github.com/renevo/actor/remote"
+)
+
+// authority is a self-signed certificate authority for tests.
+type authority struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pool *x509.CertPool
+}
+
+func newAuthority(t *testing.T) *authority {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test authority"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+
+	return &authority{cert: cert, key: key, pool: pool}
+}
+
+// config returns a mutual TLS config with a certificate for the given name issued by the authority.
+func (a *authority) config(t *testing.T, name string) *tls.Config {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, a.cert, &key.PublicKey, a.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+		RootCAs:      a.pool,
+		ClientCAs:    a.pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS13,
+	}
+}
+
+func spawnEcho(engine *actor.Engine) actor.PID {
+	return engine.SpawnFunc(func(ctx *actor.Context) {
+		if msg, ok := ctx.Message().(echo); ok {
+			ctx.Respond(echo{Text: "echo " + msg.Text})
+		}
+	}, "echo")
+}
+
+func TestRemoteMutualTLS(t *testing.T) {
+	is := is.New(t)
+
+	ca := newAuthority(t)
+	a := newEngine(t, remote.Config{TLS: ca.config(t, "a")})
+	b := newEngine(t, remote.Config{TLS: ca.config(t, "b")})
+
+	// only the certificate the other engine presented is trusted
+	var peer string
+	b2 := newEngine(t, remote.Config{
+		TLS: ca.config(t, "b2"),
+		Authorizer: remote.AuthorizerFunc(func(d remote.Delivery) error {
+			peer = d.TLS.PeerCertificates[0].Subject.CommonName
+			return nil
+		}),
+	})
+
+	resp, err := a.Request(spawnEcho(b), echo{Text: "hello"}, time.Second)
+	is.NoErr(err)
+	is.Equal(echo{Text: "echo hello"}, resp)
+
+	_, err = a.Request(spawnEcho(b2), echo{Text: "hello"}, time.Second)
+	is.NoErr(err)
+	is.Equal("a", peer)
+
+	// engines without a certificate from the authority can't connect
+	other := newEngine(t, remote.Config{TLS: newAuthority(t).config(t, "other")})
+	_, err = other.Request(spawnEcho(b), echo{Text: "hello"}, 100*time.Millisecond)
+	is.True(err != nil)
+
+	plain := newEngine(t, remote.Config{})
+	_, err = plain.Request(spawnEcho(b), echo{Text: "hello"}, 100*time.Millisecond)
+	is.True(err != nil)
+}
+
+type secret struct{}
+
+func init() {
+	remote.Register(secret{})
+}
+
+func TestAuthorizer(t *testing.T) {
+	is := is.New(t)
+
+	a := newEngine(t, remote.Config{})
+	denied := newEngine(t, remote.Config{})
+	b := newEngine(t, remote.Config{Authorizer: remote.Authorizers(
+		remote.DenySenders(denied.Address()),
+		remote.DenyMessages(secret{}),
+	)})
+
+	pid := b.SpawnFunc(func(ctx *actor.Context) {
+		switch ctx.Message().(type) {
+		case echo, secret:
+			ctx.Respond(ctx.Message())
+		}
+	}, "echo")
+
+	_, err := a.Request(pid, echo{Text: "hello"}, time.Second)
+	is.NoErr(err)
+
+	_, err = a.Request(pid, secret{}, 100*time.Millisecond)
+	is.True(err != nil) // the message type is denied
+
+	_, err = denied.Request(pid, echo{Text: "hello"}, 100*time.Millisecond)
+	is.True(err != nil) // the sender is denied
+}
+
+func TestAllowPeers(t *testing.T) {
+	is := is.New(t)
+
+	ca := newAuthority(t)
+	a := newEngine(t, remote.Config{TLS: ca.config(t, "a")})
+	c := newEngine(t, remote.Config{TLS: ca.config(t, "c")})
+	b := newEngine(t, remote.Config{TLS: ca.config(t, "b"), Authorizer: remote.AllowPeers("a")})
+	pid := spawnEcho(b)
+
+	_, err := a.Request(pid, echo{Text: "hello"}, time.Second)
+	is.NoErr(err)
+
+	_, err = c.Request(pid, echo{Text: "hello"}, 100*time.Millisecond)
+	is.True(err != nil) // c has a valid certificate, but isn't allowed
+
+	// without TLS there is no verified peer
+	plain := newEngine(t, remote.Config{Authorizer: remote.AllowPeers("a")})
+	_, err = newEngine(t, remote.Config{}).Request(spawnEcho(plain), echo{Text: "hello"}, 100*time.Millisecond)
+	is.True(err != nil)
+}
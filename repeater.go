@@ -16,20 +16,22 @@ type Repeater struct {
 }
 
 func (r Repeater) start() {
-	go func() {
-		t := time.NewTicker(r.interval)
-		defer t.Stop()
+	clock := r.engine.options.Clock
 
-		for {
-			select {
-			case <-t.C:
-				r.engine.send(r.ctx, r.to, r.msg, r.from)
+	var tick func()
+	tick = func() {
+		select {
+		case <-r.stopCh:
+			return
 
-			case <-r.stopCh:
-				return
-			}
+		default:
 		}
-	}()
+
+		r.engine.send(r.ctx, r.to, r.msg, r.from)
+		clock.AfterFunc(r.interval, tick)
+	}
+
+	clock.AfterFunc(r.interval, tick)
 }
 
 // Stop the Repeater. This will panic if called more than once.
@@ -23,7 +23,7 @@ func newResponse(ctx context.Context, engine *Engine, timeout time.Duration) *re
 		ctx:     ctx,
 		result:  make(chan any, 1),
 		timeout: timeout,
-		pid:     NewPID(engine.pid.Address, "response", strconv.Itoa(rand.Intn(100_000))),
+		pid:     NewPID(engine.address, "response", strconv.Itoa(rand.Intn(100_000))),
 	}
 
 	if r.ctx == nil {
@@ -34,17 +34,20 @@ func newResponse(ctx context.Context, engine *Engine, timeout time.Duration) *re
 }
 
 func (r *response) waitForResult() (any, error) {
-	ctx, cancel := context.WithTimeout(r.ctx, r.timeout)
+	timeout := make(chan struct{})
+	timer := r.engine.options.Clock.AfterFunc(r.timeout, func() { close(timeout) })
 	defer func() {
-		cancel()
+		timer.Stop()
 		r.engine.registry.remove(r.pid)
 	}()
 
 	select {
 	case resp := <-r.result:
 		return resp, nil
-	case <-ctx.Done():
-		return nil, ctx.Err()
+	case <-r.ctx.Done():
+		return nil, r.ctx.Err()
+	case <-timeout:
+		return nil, context.DeadlineExceeded
 	}
 }
 
@@ -57,16 +60,28 @@ func (r *response) Shutdown(_ *sync.WaitGroup) {}
 func (r *response) Start()                     {}
 func (r *response) Process(*Envelope)          {}
 
+// registerResponse registers a new response, trying another id when the random one is already in use.
+func (e *Engine) registerResponse(ctx context.Context, timeout time.Duration) *response {
+	for {
+		resp := newResponse(ctx, e, timeout)
+		if err := e.registry.add(resp); err == nil {
+			return resp
+		}
+	}
+}
+
 func (e *Engine) Request(to PID, msg any, timeout time.Duration) (any, error) {
-	resp := newResponse(e.options.Context, e, timeout)
-	e.registry.add(resp)
+	if e.stopping.Load() {
+		return nil, ErrEngineStopping
+	}
+
+	resp := e.registerResponse(e.options.Context, timeout)
 	e.send(e.options.Context, to, msg, resp.PID())
 	return resp.waitForResult()
 }
 
 func (c *Context) Request(to PID, msg any, timeout time.Duration) (any, error) {
-	resp := newResponse(c.ctx, c.engine, timeout)
-	c.engine.registry.add(resp)
+	resp := c.engine.registerResponse(c.ctx, timeout)
 	c.engine.send(c.ctx, to, msg, resp.PID())
 	return resp.waitForResult()
 }
@@ -0,0 +1,94 @@
+package actor
+
+import (
+	"reflect"
+)
+
+// Hashable is implemented by messages that carry their own routing key.
+type Hashable interface {
+	HashKey() string
+}
+
+// KeyExtractor returns the routing key for a message, false should be returned when the message has no key.
+type KeyExtractor func(msg any) (string, bool)
+
+// AddRoutee is sent to a router to add a routee.
+type AddRoutee struct {
+	PID PID
+}
+
+// RemoveRoutee is sent to a router to remove a routee.
+type RemoveRoutee struct {
+	PID PID
+}
+
+type consistentHashRouter struct {
+	ring    *HashRing
+	routees map[string]PID
+	extract KeyExtractor
+}
+
+// NewConsistentHashRouter creates a Receiver that routes messages to the given routees by the message key.
+// All messages with the same key will be routed to the same routee, as long as the set of routees doesn't change.
+// When extract is nil, messages must implement Hashable, messages without a key are sent to the deadletter.
+func NewConsistentHashRouter(extract KeyExtractor, routees ...PID) Receiver {
+	r := &consistentHashRouter{
+		ring:    NewHashRing(defaultVirtualNodes),
+		routees: make(map[string]PID, len(routees)),
+		extract: extract,
+	}
+
+	for _, pid := range routees {
+		r.add(pid)
+	}
+
+	return r
+}
+
+func (r *consistentHashRouter) Receive(ctx *Context) {
+	switch msg := ctx.Message().(type) {
+	case Initialized, Started, Stopped, Restarting, Restarted:
+
+	case AddRoutee:
+		r.add(msg.PID)
+
+	case RemoveRoutee:
+		r.ring.Remove(msg.PID.String())
+		delete(r.routees, msg.PID.String())
+
+	default:
+		key, ok := r.key(msg)
+		if !ok {
+			ctx.Log().Warn("Router received message without a routing key.", "type", reflect.TypeOf(msg))
+			ctx.engine.send(ctx.ctx, ctx.engine.deadletter, msg, ctx.sender)
+			return
+		}
+
+		member, ok := r.ring.Get(key)
+		if !ok {
+			ctx.Log().Warn("Router has no routees.", "type", reflect.TypeOf(msg))
+			ctx.engine.send(ctx.ctx, ctx.engine.deadletter, msg, ctx.sender)
+			return
+		}
+
+		// keep the original sender so routees can respond directly
+		ctx.engine.send(ctx.ctx, r.routees[member], msg, ctx.sender)
+	}
+}
+
+func (r *consistentHashRouter) add(pid PID) {
+	r.routees[pid.String()] = pid
+	r.ring.Add(pid.String())
+}
+
+func (r *consistentHashRouter) key(msg any) (string, bool) {
+	if r.extract != nil {
+		return r.extract(msg)
+	}
+
+	if h, ok := msg.(Hashable); ok {
+		return h.HashKey(), true
+	}
+
+	return "", false
+}
@@ -0,0 +1,132 @@
+package actor_test
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/renevo/actor"
+)
+
+type entityMessage struct {
+	entity string
+}
+
+func (m entityMessage) HashKey() string {
+	return m.entity
+}
+
+func TestHashRingMinimalMovement(t *testing.T) {
+	is := is.New(t)
+
+	ring := actor.NewHashRing(0)
+	ring.Add("a", "b", "c")
+
+	before := make(map[string]string)
+	for i := 0; i < 1000; i++ {
+		key := strconv.Itoa(i)
+		member, ok := ring.Get(key)
+		is.True(ok)
+		before[key] = member
+	}
+
+	ring.Remove("c")
+	is.Equal([]string{"a", "b"}, ring.Members())
+
+	for key, member := range before {
+		after, ok := ring.Get(key)
+		is.True(ok)
+		if member != "c" {
+			is.Equal(member, after) // keys owned by remaining members should not move
+		}
+	}
+
+	empty := actor.NewHashRing(0)
+	_, ok := empty.Get("foo")
+	is.True(!ok) // empty ring has no owner
+}
+
+func TestHashRingOrderIndependent(t *testing.T) {
+	is := is.New(t)
+
+	forward := actor.NewHashRing(0)
+	forward.Add("a", "b")
+	forward.Add("c")
+
+	backward := actor.NewHashRing(0)
+	backward.Add("c")
+	backward.Add("b", "a")
+
+	for i := 0; i < 1000; i++ {
+		key := strconv.Itoa(i)
+		want, _ := forward.Get(key)
+		got, _ := backward.Get(key)
+		is.Equal(want, got) // the same members route the same keys, whatever order they were added in
+	}
+}
+
+func TestConsistentHashRouter(t *testing.T) {
+	is := is.New(t)
+
+	engine := actor.NewEngine()
+	wg := &sync.WaitGroup{}
+	mu := sync.Mutex{}
+	received := make(map[string]map[string]bool)
+
+	var routees []actor.PID
+	for i := 0; i < 4; i++ {
+		pid := engine.SpawnFunc(func(ctx *actor.Context) {
+			if msg, ok := ctx.Message().(entityMessage); ok {
+				mu.Lock()
+				if received[msg.entity] == nil {
+					received[msg.entity] = make(map[string]bool)
+				}
+				received[msg.entity][ctx.PID().String()] = true
+				mu.Unlock()
+				wg.Done()
+			}
+		}, "TestConsistentHashRouter", actor.WithTags("worker", strconv.Itoa(i)))
+		routees = append(routees, pid)
+	}
+
+	router := engine.Spawn(actor.NewConsistentHashRouter(nil, routees...), "TestConsistentHashRouter", actor.WithTags("router"))
+
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		engine.Send(context.Background(), router, entityMessage{entity: strconv.Itoa(i % 10)})
+	}
+	wg.Wait()
+
+	is.Equal(10, len(received))
+	for _, workers := range received {
+		is.Equal(1, len(workers)) // every entity should be handled by a single worker
+	}
+
+	engine.ShutdownAndWait()
+}
+
+func TestConsistentHashRouterKeyExtractor(t *testing.T) {
+	is := is.New(t)
+
+	engine := actor.NewEngine()
+	worker := engine.SpawnFunc(func(ctx *actor.Context) {
+		if msg, ok := ctx.Message().(string); ok {
+			ctx.Respond("hello " + msg)
+		}
+	}, "TestConsistentHashRouterKeyExtractor", actor.WithTags("worker"))
+
+	router := engine.Spawn(actor.NewConsistentHashRouter(func(msg any) (string, bool) {
+		s, ok := msg.(string)
+		return s, ok
+	}), "TestConsistentHashRouterKeyExtractor", actor.WithTags("router"))
+	engine.Send(context.Background(), router, actor.AddRoutee{PID: worker})
+
+	resp, err := engine.Request(router, "world", time.Second)
+	is.NoErr(err)
+	is.Equal("hello world", resp) // routee responds to the original sender
+
+	engine.ShutdownAndWait()
+}
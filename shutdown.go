@@ -0,0 +1,234 @@
+package actor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrEngineStopping = errors.New("engine stopping")
+)
+
+const (
+	defaultShutdownTimeout = 5 * time.Second
+
+	// shutdownPollInterval is how often inboxes are checked while draining, it only yields and isn't a timeout, so it doesn't use the Clock.
+	shutdownPollInterval = time.Millisecond
+)
+
+// ShutdownPhase is a step of ShutdownContext that can time out.
+type ShutdownPhase string
+
+const (
+	// ShutdownPhaseDrain waits for the actors to process everything in their inboxes.
+	ShutdownPhaseDrain ShutdownPhase = "drain"
+	// ShutdownPhaseActors stops the actors, children before their parents.
+	ShutdownPhaseActors ShutdownPhase = "actors"
+	// ShutdownPhaseSystem stops the engine and deadletter actors.
+	ShutdownPhaseSystem ShutdownPhase = "system"
+)
+
+// ShutdownError is returned by ShutdownContext when actors didn't finish a phase in time.
+type ShutdownError struct {
+	Laggards map[ShutdownPhase][]PID
+}
+
+func (e *ShutdownError) Error() string {
+	var phases []string
+	for _, phase := range []ShutdownPhase{ShutdownPhaseDrain, ShutdownPhaseActors, ShutdownPhaseSystem} {
+		pids := e.Laggards[phase]
+		if len(pids) == 0 {
+			continue
+		}
+
+		ids := make([]string, len(pids))
+		for i, pid := range pids {
+			ids[i] = pid.String()
+		}
+		phases = append(phases, fmt.Sprintf("%s: %s", phase, strings.Join(ids, ", ")))
+	}
+
+	return "shutdown: actors did not finish in time (" + strings.Join(phases, "; ") + ")"
+}
+
+func (e *ShutdownError) add(phase ShutdownPhase, pid PID) {
+	if e.Laggards == nil {
+		e.Laggards = make(map[ShutdownPhase][]PID)
+	}
+	e.Laggards[phase] = append(e.Laggards[phase], pid)
+}
+
+// ShutdownContext shuts the engine down in phases, each phase is limited to the timeout set with WithShutdownTimeout, and all of them to the context.
+//
+// First the engine stops accepting messages through Send, SendWithSender, Publish and Request, so only actors, remotes and Relay can send messages.
+// Then it waits for every inbox to be empty, stops the actors with their children before their parents, and finally stops the engine and deadletter actors.
+//
+// Actors that still have messages when the drain phase times out aren't force stopped, they are stopped with everyone else in the next phase.
+// Actors that don't stop in time are force stopped: they are removed from the engine and their remaining messages are deadlettered, although their receiver may still be running.
+// A *ShutdownError listing the actors that didn't finish each phase is returned.
+func (e *Engine) ShutdownContext(ctx context.Context) error {
+	e.stopping.Store(true)
+
+	laggards := &ShutdownError{}
+
+	// drain
+	expired, done := e.shutdownPhase(ctx)
+	for _, proc := range e.drain(expired) {
+		laggards.add(ShutdownPhaseDrain, proc.pid)
+	}
+	done()
+
+	// actors, the deepest first so children stop before their parents
+	expired, done = e.shutdownPhase(ctx)
+	for {
+		e.forgetPassivated()
+
+		procs := e.actors()
+		if len(procs) == 0 {
+			break
+		}
+
+		depth := 0
+		for _, proc := range procs {
+			depth = max(depth, proc.depth())
+		}
+
+		var deepest []*processor
+		for _, proc := range procs {
+			if proc.depth() == depth {
+				deepest = append(deepest, proc)
+			}
+		}
+
+		for _, proc := range e.stopActors(deepest, expired) {
+			laggards.add(ShutdownPhaseActors, proc.pid)
+		}
+	}
+	done()
+
+	// system
+	var system []*processor
+	for _, pid := range []PID{e.pid, e.deadletter} {
+		if proc, ok := e.registry.get(pid).(*processor); ok {
+			system = append(system, proc)
+		}
+	}
+
+	expired, done = e.shutdownPhase(ctx)
+	for _, proc := range e.stopActors(system, expired) {
+		laggards.add(ShutdownPhaseSystem, proc.pid)
+	}
+	done()
+
+	if e.options.Remote != nil {
+		e.options.Remote.Stop()
+	}
+
+	if len(laggards.Laggards) > 0 {
+		return laggards
+	}
+
+	return nil
+}
+
+// shutdownPhase returns a channel that is closed when the phase has timed out or the context is done, done must be called once the phase is over.
+func (e *Engine) shutdownPhase(ctx context.Context) (<-chan struct{}, func()) {
+	expired := make(chan struct{})
+	var once sync.Once
+	expire := func() {
+		once.Do(func() { close(expired) })
+	}
+
+	timer := e.options.Clock.AfterFunc(e.options.ShutdownTimeout, expire)
+	unregister := context.AfterFunc(ctx, expire)
+
+	return expired, func() {
+		timer.Stop()
+		unregister()
+	}
+}
+
+// actors returns the running actors, without the engine and deadletter actors.
+func (e *Engine) actors() []*processor {
+	var procs []*processor
+	for pid, proc := range e.registry.copy() {
+		internalProcessor, ok := proc.(*processor)
+		if !ok || pid.Equals(e.pid) || pid.Equals(e.deadletter) {
+			continue
+		}
+		procs = append(procs, internalProcessor)
+	}
+
+	sort.Slice(procs, func(i, j int) bool { return procs[i].pid.ID < procs[j].pid.ID })
+
+	return procs
+}
+
+// drain waits for the inboxes of all actors to be empty, returning the actors that still had messages when the phase expired.
+func (e *Engine) drain(expired <-chan struct{}) []*processor {
+	for {
+		var busy []*processor
+		for _, proc := range e.actors() {
+			if !proc.inbox.idle() {
+				busy = append(busy, proc)
+			}
+		}
+
+		if len(busy) == 0 {
+			return nil
+		}
+
+		select {
+		case <-expired:
+			return busy
+
+		case <-time.After(shutdownPollInterval):
+		}
+	}
+}
+
+// stopActors poisons the actors and waits for them, actors that haven't stopped when the phase expires are force stopped and returned.
+func (e *Engine) stopActors(procs []*processor, expired <-chan struct{}) []*processor {
+	stopped := make([]<-chan struct{}, len(procs))
+	for i, proc := range procs {
+		stopped[i] = e.Poison(proc.pid, nil)
+	}
+
+	var laggards []*processor
+	for i, proc := range procs {
+		select {
+		case <-stopped[i]:
+			continue
+
+		case <-expired:
+		}
+
+		// the phase may have expired just as it stopped
+		select {
+		case <-stopped[i]:
+
+		default:
+			proc.context.logger.Warn("Actor did not stop in time, forcing it to stop.")
+			proc.forceStop()
+			laggards = append(laggards, proc)
+		}
+	}
+
+	return laggards
+}
+
+// forgetPassivated forgets all passivated actors, they won't be coming back.
+func (e *Engine) forgetPassivated() {
+	var passivated []PID
+	e.passivated.ForEach(func(id string, _ *passivation) {
+		passivated = append(passivated, PID{Address: e.address, ID: id})
+	})
+	for _, pid := range passivated {
+		e.forget(pid)
+	}
+}
@@ -0,0 +1,104 @@
+package actor
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrUnknownKind = errors.New("unknown kind")
+)
+
+const (
+	defaultSpawnRemoteTimeout = 5 * time.Second
+)
+
+// SpawnRequest asks the engine actor of another engine to spawn an actor from a registered kind, it is answered with a SpawnResponse.
+type SpawnRequest struct {
+	Kind        string
+	Name        string
+	Tags        []string
+	SpawnPolicy SpawnPolicy
+}
+
+// SpawnResponse is the answer to a SpawnRequest.
+type SpawnResponse struct {
+	PID PID
+	// Error is empty when the actor was spawned.
+	Error string
+}
+
+// SpawnRemoteOptions are the options for spawning an actor on another engine, options that are functions can't be sent to it.
+type SpawnRemoteOptions struct {
+	Tags        []string
+	SpawnPolicy SpawnPolicy
+	// Timeout for the other engine to answer, defaults to 5 seconds.
+	Timeout time.Duration
+}
+
+// SpawnKind spawns an actor with a new receiver from the Props of a registered kind, ErrUnknownKind is returned when the kind isn't registered.
+// Unlike grains, the actor is spawned with the given name and is not passivated unless its kind was registered with an idle timeout.
+func (e *Engine) SpawnKind(kind string, name string, opts ...Option) (PID, error) {
+	k, ok := e.kinds.Get(kind)
+	if !ok {
+		return PID{}, fmt.Errorf("%w: %s", ErrUnknownKind, kind)
+	}
+
+	return e.TrySpawnProps(k.props, name, opts...)
+}
+
+// SpawnRemote spawns an actor from a kind registered on the engine at address, returning its PID on that engine.
+// ErrUnknownKind is returned when the kind isn't registered there, and ErrDuplicateActor when the actor already exists and the spawn policy is SpawnPolicyFail.
+func (e *Engine) SpawnRemote(address string, kind string, name string, opts SpawnRemoteOptions) (PID, error) {
+	if address == e.address || address == LocalAddress {
+		return e.SpawnKind(kind, name, WithTags(opts.Tags...), WithSpawnPolicy(opts.SpawnPolicy))
+	}
+
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultSpawnRemoteTimeout
+	}
+
+	resp, err := e.Request(NewPID(address, "engine"), SpawnRequest{
+		Kind:        kind,
+		Name:        name,
+		Tags:        opts.Tags,
+		SpawnPolicy: opts.SpawnPolicy,
+	}, opts.Timeout)
+	if err != nil {
+		return PID{}, fmt.Errorf("unable to spawn %s on %s: %w", kind, address, err)
+	}
+
+	spawned, ok := resp.(SpawnResponse)
+	if !ok {
+		return PID{}, fmt.Errorf("unable to spawn %s on %s: unexpected response %T", kind, address, resp)
+	}
+
+	if spawned.Error != "" {
+		return spawned.PID, spawnError(spawned.Error)
+	}
+
+	return spawned.PID, nil
+}
+
+// spawnRequested handles a SpawnRequest from another engine.
+func (e *Engine) spawnRequested(req SpawnRequest) SpawnResponse {
+	pid, err := e.SpawnKind(req.Kind, req.Name, WithTags(req.Tags...), WithSpawnPolicy(req.SpawnPolicy))
+	if err != nil {
+		return SpawnResponse{PID: pid, Error: err.Error()}
+	}
+
+	return SpawnResponse{PID: pid}
+}
+
+// spawnError turns the error from another engine back into one that can be checked with errors.Is.
+func spawnError(msg string) error {
+	for _, err := range []error{ErrUnknownKind, ErrDuplicateActor} {
+		if detail, ok := strings.CutPrefix(msg, err.Error()); ok {
+			return fmt.Errorf("%w%s", err, detail)
+		}
+	}
+
+	return errors.New(msg)
+}
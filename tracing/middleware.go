@@ -0,0 +1,40 @@
+package tracing
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/renevo/actor"
+)
+
+// Middleware starts a span for every message processed by the actor.
+// The span is a child of the span carried in the message context, and the actor context is updated so any messages sent while processing carry the new span.
+// Lifecycle messages are not traced.
+func Middleware(tracer *Tracer) actor.Middleware {
+	return func(next actor.ReceiverFunc) actor.ReceiverFunc {
+		return func(ctx *actor.Context) {
+			switch ctx.Message().(type) {
+			case actor.Initialized, actor.Started, actor.Stopped, actor.Restarting, actor.Restarted:
+				next(ctx)
+				return
+			}
+
+			msgType := reflect.TypeOf(ctx.Message()).String()
+			spanCtx, span := tracer.Start(ctx.Context(), "receive "+msgType)
+			span.SetAttribute("actor.pid", ctx.PID().String())
+			span.SetAttribute("actor.sender", ctx.Sender().String())
+			span.SetAttribute("message.type", msgType)
+
+			defer func() {
+				if v := recover(); v != nil {
+					span.SetError(fmt.Sprint(v))
+					span.Finish()
+					panic(v)
+				}
+				span.Finish()
+			}()
+
+			next(ctx.WithContext(spanCtx))
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"strings"
+)
+
+const (
+	traceparentHeader  = "traceparent"
+	traceparentVersion = "00"
+	traceparentSampled = "01"
+)
+
+// TraceContext propagates span contexts using the W3C traceparent format.
+// Remote transports call Inject before an envelope leaves the engine, and Extract when it arrives, so spans stay connected across engines.
+type TraceContext struct{}
+
+// Inject the span context carried by ctx into the carrier.
+func (TraceContext) Inject(ctx context.Context, carrier map[string]string) {
+	sc := SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+
+	carrier[traceparentHeader] = strings.Join([]string{traceparentVersion, sc.TraceID.String(), sc.SpanID.String(), traceparentSampled}, "-")
+}
+
+// Extract a span context from the carrier, and return a copy of ctx that carries it as a remote parent.
+// ctx is returned as is when the carrier does not hold a valid span context.
+func (TraceContext) Extract(ctx context.Context, carrier map[string]string) context.Context {
+	parts := strings.Split(carrier[traceparentHeader], "-")
+	if len(parts) != 4 || parts[0] != traceparentVersion {
+		return ctx
+	}
+
+	var sc SpanContext
+	if len(parts[1]) != hex.EncodedLen(len(sc.TraceID)) || len(parts[2]) != hex.EncodedLen(len(sc.SpanID)) {
+		return ctx
+	}
+	if _, err := hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil {
+		return ctx
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil {
+		return ctx
+	}
+
+	if !sc.IsValid() {
+		return ctx
+	}
+	sc.Remote = true
+
+	return ContextWithSpanContext(ctx, sc)
+}
@@ -0,0 +1,176 @@
+// Package tracing provides OpenTelemetry style tracing for actors.
+//
+// A span is started for every message an actor processes, with the span of the sender (carried in the message context.Context) as its parent,
+// so a chain of Send/Request calls between actors becomes a single trace.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// TraceID identifies a trace.
+type TraceID [16]byte
+
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+
+// IsZero returns true if the TraceID is not set.
+func (t TraceID) IsZero() bool { return t == TraceID{} }
+
+// SpanID identifies a span within a trace.
+type SpanID [8]byte
+
+func (s SpanID) String() string { return hex.EncodeToString(s[:]) }
+
+// IsZero returns true if the SpanID is not set.
+func (s SpanID) IsZero() bool { return s == SpanID{} }
+
+// SpanContext is the part of a span that is propagated between actors and engines.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Remote  bool
+}
+
+// IsValid returns true if both the TraceID and SpanID are set.
+func (sc SpanContext) IsValid() bool {
+	return !sc.TraceID.IsZero() && !sc.SpanID.IsZero()
+}
+
+// Span is a single unit of work within a trace.
+type Span struct {
+	Name       string
+	Context    SpanContext
+	Parent     SpanContext
+	Start      time.Time
+	End        time.Time
+	Attributes map[string]string
+	Error      string
+
+	mu     sync.Mutex
+	tracer *Tracer
+	ended  bool
+}
+
+// SetAttribute sets an attribute on the span.
+func (s *Span) SetAttribute(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Attributes[key] = value
+}
+
+// SetError marks the span as failed.
+func (s *Span) SetError(err string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Error = err
+}
+
+// Finish ends the span and exports it, calling Finish more than once has no effect.
+func (s *Span) Finish() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.End = time.Now()
+
+	data := SpanData{
+		Name:       s.Name,
+		Context:    s.Context,
+		Parent:     s.Parent,
+		Start:      s.Start,
+		End:        s.End,
+		Attributes: make(map[string]string, len(s.Attributes)),
+		Error:      s.Error,
+	}
+	for k, v := range s.Attributes {
+		data.Attributes[k] = v
+	}
+	s.mu.Unlock()
+
+	s.tracer.exporter.Export(data)
+}
+
+// SpanData is an immutable copy of a finished span that is handed to an Exporter.
+type SpanData struct {
+	Name       string
+	Context    SpanContext
+	Parent     SpanContext
+	Start      time.Time
+	End        time.Time
+	Attributes map[string]string
+	Error      string
+}
+
+// Exporter receives finished spans.
+type Exporter interface {
+	Export(span SpanData)
+}
+
+// Tracer creates spans and hands them to an Exporter when they are finished.
+type Tracer struct {
+	exporter Exporter
+}
+
+// NewTracer creates a new Tracer that exports to the given Exporter.
+func NewTracer(exporter Exporter) *Tracer {
+	return &Tracer{exporter: exporter}
+}
+
+// Start a new span as a child of the span in the given context, a new trace is started if there is no span in the context.
+func (t *Tracer) Start(ctx context.Context, name string) (context.Context, *Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	parent := SpanContextFromContext(ctx)
+	span := &Span{
+		Name:       name,
+		Parent:     parent,
+		Start:      time.Now(),
+		Attributes: make(map[string]string),
+		tracer:     t,
+	}
+
+	span.Context.TraceID = parent.TraceID
+	if !parent.IsValid() {
+		span.Context.TraceID = newTraceID()
+	}
+	span.Context.SpanID = newSpanID()
+
+	return ContextWithSpanContext(ctx, span.Context), span
+}
+
+type spanContextKey struct{}
+
+// ContextWithSpanContext returns a copy of ctx that carries the given SpanContext.
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// SpanContextFromContext returns the SpanContext carried by ctx, the returned SpanContext will not be valid if there is none.
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if ctx == nil {
+		return SpanContext{}
+	}
+
+	sc, _ := ctx.Value(spanContextKey{}).(SpanContext)
+	return sc
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+	_, _ = rand.Read(id[:])
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	_, _ = rand.Read(id[:])
+	return id
+}
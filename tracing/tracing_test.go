@@ -0,0 +1,124 @@
+package tracing_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/renevo/actor"
+	"github.com/renevo/actor/tracing"
+)
+
+func TestMiddlewareLinksSpans(t *testing.T) {
+	is := is.New(t)
+
+	exporter := tracing.NewInMemoryExporter()
+	tracer := tracing.NewTracer(exporter)
+	engine := actor.NewEngine()
+	traced := actor.WithMiddleware(tracing.Middleware(tracer))
+
+	backend := engine.SpawnFunc(func(ctx *actor.Context) {
+		if msg, ok := ctx.Message().(string); ok && msg != "linked" {
+			ctx.Respond("backend " + msg)
+		}
+	}, "TestMiddlewareLinksSpans", actor.WithTags("backend"), traced)
+
+	frontend := engine.SpawnFunc(func(ctx *actor.Context) {
+		if msg, ok := ctx.Message().(string); ok {
+			resp, err := ctx.Request(backend, msg, time.Second)
+			if err != nil {
+				panic(err)
+			}
+			ctx.Respond(resp)
+		}
+	}, "TestMiddlewareLinksSpans", actor.WithTags("frontend"), traced)
+
+	rootCtx, root := tracer.Start(context.Background(), "root")
+	resp, err := engine.Request(frontend, "hello", time.Second)
+	is.NoErr(err)
+	is.Equal("backend hello", resp)
+
+	// requests use the engine context, so send with the root span directly
+	done := make(chan struct{})
+	receiver := engine.SpawnFunc(func(ctx *actor.Context) {
+		if _, ok := ctx.Message().(int); ok {
+			ctx.Send(ctx.Context(), backend, "linked")
+			close(done)
+		}
+	}, "TestMiddlewareLinksSpans", actor.WithTags("sender"), traced)
+	engine.Send(rootCtx, receiver, 1)
+	<-done
+	root.Finish()
+
+	engine.ShutdownAndWait()
+
+	spans := exporter.Spans()
+	byPID := make(map[string][]tracing.SpanData)
+	for _, span := range spans {
+		byPID[span.Attributes["actor.pid"]] = append(byPID[span.Attributes["actor.pid"]], span)
+	}
+
+	front := byPID["local.TestMiddlewareLinksSpans.frontend"]
+	back := byPID["local.TestMiddlewareLinksSpans.backend"]
+	sender := byPID["local.TestMiddlewareLinksSpans.sender"]
+	is.Equal(1, len(front))
+	is.Equal(2, len(back))
+	is.Equal(1, len(sender))
+
+	// frontend -> backend request is a single trace
+	is.Equal(front[0].Context.TraceID, back[0].Context.TraceID)
+	is.Equal(front[0].Context.SpanID, back[0].Parent.SpanID)
+
+	// engine send with a span in the context links to that span
+	is.Equal(root.Context.SpanID, sender[0].Parent.SpanID)
+	is.Equal(sender[0].Context.SpanID, back[1].Parent.SpanID)
+	is.Equal(root.Context.TraceID, back[1].Context.TraceID)
+}
+
+func TestMiddlewareRecordsPanics(t *testing.T) {
+	is := is.New(t)
+
+	exporter := tracing.NewInMemoryExporter()
+	engine := actor.NewEngine()
+	done := make(chan struct{})
+
+	pid := engine.SpawnFunc(func(ctx *actor.Context) {
+		switch ctx.Message().(type) {
+		case string:
+			panic("boom")
+		case actor.Stopped:
+			close(done)
+		}
+	}, "TestMiddlewareRecordsPanics", actor.WithMaxRestarts(0), actor.WithMiddleware(tracing.Middleware(tracing.NewTracer(exporter))))
+
+	engine.Send(context.Background(), pid, "panic")
+	<-done
+
+	spans := exporter.Spans()
+	is.Equal(1, len(spans))
+	is.Equal("boom", spans[0].Error)
+}
+
+func TestTraceContextPropagation(t *testing.T) {
+	is := is.New(t)
+
+	tracer := tracing.NewTracer(tracing.NewInMemoryExporter())
+	ctx, span := tracer.Start(context.Background(), "outbound")
+
+	carrier := make(map[string]string)
+	tracing.TraceContext{}.Inject(ctx, carrier)
+	is.Equal("00-"+span.Context.TraceID.String()+"-"+span.Context.SpanID.String()+"-01", carrier["traceparent"])
+
+	remote := tracing.TraceContext{}.Extract(context.Background(), carrier)
+	sc := tracing.SpanContextFromContext(remote)
+	is.True(sc.Remote)
+	is.Equal(span.Context.TraceID, sc.TraceID)
+
+	_, child := tracer.Start(remote, "inbound")
+	is.Equal(span.Context.TraceID, child.Context.TraceID)
+	is.Equal(span.Context.SpanID, child.Parent.SpanID)
+
+	invalid := tracing.TraceContext{}.Extract(context.Background(), map[string]string{"traceparent": "00-zz-zz-01"})
+	is.True(!tracing.SpanContextFromContext(invalid).IsValid())
+}
@@ -0,0 +1,67 @@
+package actor
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+// TypedContext is the Context handed to typed actors, all of the untyped Context functionality is available.
+type TypedContext[M any] struct {
+	*Context
+}
+
+// TypedReceiverFunc handles messages of type M.
+type TypedReceiverFunc[M any] func(*TypedContext[M], M)
+
+// TypedPID is a PID that only accepts messages of type M.
+// The underlying PID is embedded, so a TypedPID can be used anywhere an untyped PID is expected.
+type TypedPID[M any] struct {
+	PID
+	engine *Engine
+}
+
+// NewTypedPID wraps an untyped PID so messages can only be sent to it as type M.
+func NewTypedPID[M any](engine *Engine, pid PID) TypedPID[M] {
+	return TypedPID[M]{PID: pid, engine: engine}
+}
+
+// Send a message to the typed actor.
+func (p TypedPID[M]) Send(ctx context.Context, msg M) {
+	p.engine.Send(ctx, p.PID, msg)
+}
+
+// Request sends a message to the typed actor and waits for a response.
+func (p TypedPID[M]) Request(msg M, timeout time.Duration) (any, error) {
+	return p.engine.Request(p.PID, msg, timeout)
+}
+
+// SpawnTyped spawns an actor that only handles messages of type M.
+// Lifecycle messages are only delivered when they are assignable to M, any other message is sent to the deadletter.
+func SpawnTyped[M any](engine *Engine, fn TypedReceiverFunc[M], name string, opts ...Option) TypedPID[M] {
+	pid := engine.Spawn(typedReceiver(fn), name, opts...)
+	return NewTypedPID[M](engine, pid)
+}
+
+// SpawnTypedChild spawns a typed actor as a child of the given Context.
+func SpawnTypedChild[M any](ctx *Context, fn TypedReceiverFunc[M], name string, opts ...Option) TypedPID[M] {
+	pid := ctx.Spawn(typedReceiver(fn), name, opts...)
+	return NewTypedPID[M](ctx.engine, pid)
+}
+
+func typedReceiver[M any](fn TypedReceiverFunc[M]) ReceiverFunc {
+	return func(ctx *Context) {
+		if msg, ok := ctx.Message().(M); ok {
+			fn(&TypedContext[M]{Context: ctx}, msg)
+			return
+		}
+
+		switch ctx.Message().(type) {
+		case Initialized, Started, Stopped, Restarting, Restarted:
+
+		default:
+			ctx.Log().Warn("Typed actor received unexpected message type.", "type", reflect.TypeOf(ctx.Message()))
+			ctx.engine.send(ctx.ctx, ctx.engine.deadletter, ctx.message, ctx.sender)
+		}
+	}
+}
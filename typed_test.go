@@ -0,0 +1,96 @@
+package actor_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/renevo/actor"
+)
+
+type greet struct {
+	name string
+}
+
+func TestSpawnTyped(t *testing.T) {
+	is := is.New(t)
+
+	engine := actor.NewEngine()
+	received := make(chan string, 1)
+
+	pid := actor.SpawnTyped(engine, func(ctx *actor.TypedContext[greet], msg greet) {
+		if ctx.Sender().ID == "engine" {
+			received <- msg.name
+			return
+		}
+		ctx.Respond("hello " + msg.name)
+	}, "TestSpawnTyped")
+
+	pid.Send(context.Background(), greet{name: "typed"})
+	is.Equal("typed", <-received)
+
+	resp, err := pid.Request(greet{name: "request"}, time.Second)
+	is.NoErr(err)
+	is.Equal("hello request", resp)
+
+	// interoperable with untyped PIDs, unexpected messages go to the deadletter
+	engine.Send(context.Background(), pid.PID, "not a greet")
+	engine.Send(context.Background(), pid.PID, greet{name: "untyped"})
+	is.Equal("untyped", <-received)
+
+	engine.ShutdownAndWait()
+}
+
+func TestSpawnTypedChild(t *testing.T) {
+	is := is.New(t)
+
+	engine := actor.NewEngine()
+	received := make(chan int, 1)
+
+	engine.SpawnFunc(func(ctx *actor.Context) {
+		if _, ok := ctx.Message().(actor.Started); ok {
+			child := actor.SpawnTypedChild(ctx, func(_ *actor.TypedContext[int], msg int) {
+				received <- msg
+			}, "child")
+			child.Send(ctx.Context(), 42)
+		}
+	}, "TestSpawnTypedChild")
+
+	is.Equal(42, <-received)
+
+	engine.ShutdownAndWait()
+}
+
+// deadletters counts the messages that went to the deadletter.
+type deadletters struct {
+	actor.Metrics
+	count atomic.Int32
+}
+
+func (d *deadletters) Deadletter(actor.PID, string) {
+	d.count.Add(1)
+}
+
+func TestSpawnTypedRestart(t *testing.T) {
+	is := is.New(t)
+
+	metrics := &deadletters{Metrics: actor.NewPrometheusMetrics()}
+	engine := actor.NewEngine(actor.WithMetrics(metrics), actor.WithRestartDelay(time.Millisecond))
+
+	received := make(chan int, 1)
+	pid := actor.SpawnTyped(engine, func(_ *actor.TypedContext[int], msg int) {
+		if msg == 0 {
+			panic("zero")
+		}
+		received <- msg
+	}, "TestSpawnTypedRestart")
+
+	pid.Send(context.Background(), 0)
+	pid.Send(context.Background(), 1)
+	is.Equal(1, <-received)
+
+	engine.ShutdownAndWait()
+	is.Equal(int32(0), metrics.count.Load()) // lifecycle messages of a restart aren't unexpected
+}